@@ -1,5 +1,10 @@
 package awsprofile
 
+import (
+	"errors"
+	"sync"
+)
+
 // Null values
 const (
 	EmptyString string = ""
@@ -11,54 +16,111 @@ const (
 	ErrorNotFound string = " is not found"
 )
 
-// AwsProfile provide Credentials and Configs
+// errors
+var (
+	ErrorNotFoundProfile = errors.New("profile" + ErrorNotFound)
+)
+
+// AwsProfile provide Credentials, Configs, SSOSessions and Services.
+//
+// Methods of AwsProfile are safe for concurrent use. Parse and Reload swap in newly parsed
+// Credentials, Configs, SSOSessions and Services, and do not change the ones read before,
+// so use the getters or Snapshot instead of the fields while Reload may be called.
+//
+// The stores returned by the getters, IsCredential, IsConfig and Snapshot are shared, not copied,
+// so they are read-only while other goroutines may read them. To edit and Save profiles,
+// parse a Credentials or Configs of its own, e.g. with NewConfigs and Configs.Parse.
 type AwsProfile struct {
 	Credentials *Credentials
 	Configs     *Configs
+	SSOSessions *SSOSessions
+	Services    *Services
+
+	mu       sync.RWMutex
+	reloadMu sync.Mutex
+
+	options          options
+	lenient          bool
+	credentialsState fileState
+	configState      fileState
 }
 
-// New create a AwsProfile instance
-func New() *AwsProfile {
+// New create a AwsProfile instance.
+// Without options, Parse reads the files of AWS_SHARED_CREDENTIALS_FILE and AWS_CONFIG_FILE, or the ones in the home directory.
+func New(opts ...Option) *AwsProfile {
 	awsProfile := &AwsProfile{
 		Credentials: NewCredentials(),
 		Configs:     NewConfigs(),
+		SSOSessions: NewSSOSessions(),
+		Services:    NewServices(),
+	}
+
+	for _, opt := range opts {
+		opt(&awsProfile.options)
 	}
 
 	return awsProfile
 }
 
-// Parse credential file and config file
+// Parse credential file and config file.
+// Profiles parsed before are replaced, so Parse can be called again to refresh them.
+// A file which does not exist is parsed as an empty file, and CredentialsFile and ConfigFile tell whether it is found.
+// Other errors such as permission denied are returned.
 func (a *AwsProfile) Parse() error {
-	credentialsFile, err := GetCredentialsPath()
-	if err != nil {
-		return err
-	}
+	return a.parse(false)
+}
 
-	if err = a.Credentials.Parse(credentialsFile); err != nil {
-		return err
-	}
+// ParseLenient parse credential file and config file like Parse, but it does not stop at invalid lines and values.
+// Profiles which have no errors are all parsed, and errors of both files are returned as ParseErrors.
+func (a *AwsProfile) ParseLenient() error {
+	return a.parse(true)
+}
 
-	configsFile, err := GetConfigsPath()
-	if err != nil {
-		return err
-	}
+func (a *AwsProfile) parse(lenient bool) error {
+	a.reloadMu.Lock()
+	defer a.reloadMu.Unlock()
 
-	if err = a.Configs.Parse(configsFile); err != nil {
-		return err
-	}
+	a.mu.Lock()
+	a.lenient = lenient
+	a.credentialsState = fileState{}
+	a.configState = fileState{}
+	a.mu.Unlock()
+
+	_, err := a.reload()
 
-	return nil
+	return err
+}
+
+// Snapshot get a consistent view of the profiles at the moment.
+// Reload does not change the snapshot, so it can be read while Reload is called.
+// The snapshot shares the stores with a, so do not edit them.
+func (a *AwsProfile) Snapshot() *AwsProfile {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	return &AwsProfile{
+		Credentials:      a.Credentials,
+		Configs:          a.Configs,
+		SSOSessions:      a.SSOSessions,
+		Services:         a.Services,
+		options:          a.options,
+		lenient:          a.lenient,
+		credentialsState: a.credentialsState,
+		configState:      a.configState,
+	}
 }
 
 // ProfileNames get name of profiles
 func (a *AwsProfile) ProfileNames() ([]string, error) {
 	var profileNames []string
 
-	for _, credential := range *a.Credentials {
+	snapshot := a.Snapshot()
+
+	for _, credential := range snapshot.Credentials.list {
 		profileNames = append(profileNames, credential.ProfileName)
 	}
 
-	for _, config := range *a.Configs {
+	for _, config := range snapshot.Configs.list {
 		profileNames = append(profileNames, config.ProfileName)
 	}
 
@@ -69,44 +131,58 @@ func (a *AwsProfile) ProfileNames() ([]string, error) {
 
 // GetCredentials get Credentials
 func (a *AwsProfile) GetCredentials() *Credentials {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
 	return a.Credentials
 }
 
 // GetConfigs get Configs
 func (a *AwsProfile) GetConfigs() *Configs {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
 	return a.Configs
 }
 
-// IsCredential
+// GetSSOSessions get SSOSessions
+func (a *AwsProfile) GetSSOSessions() *SSOSessions {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	return a.SSOSessions
+}
+
+// GetServices get Services
+func (a *AwsProfile) GetServices() *Services {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	return a.Services
+}
+
+// IsCredential get the credential of a profile.
+// The credential is not a copy, so changes to it are kept in Credentials and written by Save.
+// Change it only while no other goroutine reads a, since snapshots share it.
 func (a *AwsProfile) IsCredential(profile string) (bool, *Credential) {
-	var ok bool = false
-	var cred *Credential
-
-	for _, credential := range *a.Credentials {
-		if credential.ProfileName == profile {
-			ok = true
-			cred = &credential
-			break
-		}
-	}
+	credential, ok := a.GetCredentials().get(profile)
 
-	return ok, cred
+	return ok, credential
 }
 
-// IsConfig
+// IsConfig get the config of a profile.
+// The config is not a copy, so changes to it are kept in Configs and written by Save.
+// Change it only while no other goroutine reads a, since snapshots share it.
 func (a *AwsProfile) IsConfig(profile string) (bool, *Config) {
-	var ok bool = false
-	var conf *Config
-
-	for _, config := range *a.Configs {
-		if config.ProfileName == profile {
-			ok = true
-			conf = &config
-			break
-		}
-	}
+	config, ok := a.GetConfigs().get(profile)
+
+	return ok, config
+}
 
-	return ok, conf
+// notFound returns NotFoundError of a profile which is in neither credentials file nor config file
+func (a *AwsProfile) notFound(profile string) error {
+	profileNames, _ := a.ProfileNames()
+	return profileNotFound(profile, profileNames, ErrorNotFoundProfile)
 }
 
 func removeDuplicate(s []string) []string {
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"log"
 	"os"
+	"strconv"
 	"testing"
 
 	"github.com/youyo/awsprofile"
@@ -158,3 +159,69 @@ func TestAwsProfile_IsConfig(t *testing.T) {
 		}
 	}
 }
+
+func BenchmarkAwsProfile_IsConfig(b *testing.B) {
+	for _, n := range []int{10, 100, 1000, 10000} {
+		awsProfile := awsprofile.New()
+		for i := 0; i < n; i++ {
+			awsProfile.Configs.Set(awsprofile.Config{ProfileName: "profile-" + strconv.Itoa(i)})
+		}
+
+		last := "profile-" + strconv.Itoa(n-1)
+
+		b.Run(strconv.Itoa(n), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				if ok, _ := awsProfile.IsConfig(last); !ok {
+					b.Fatal(last, "is not found")
+				}
+			}
+		})
+	}
+}
+
+func TestAwsProfile_IsConfig_Pointer(t *testing.T) {
+	os.Setenv("AWS_SHARED_CREDENTIALS_FILE", "./tests/.aws/credentials")
+	os.Setenv("AWS_CONFIG_FILE", "./tests/.aws/config")
+
+	awsProfile := awsprofile.New()
+	if err := awsProfile.Parse(); err != nil {
+		t.Fatal(err)
+	}
+
+	_, config := awsProfile.IsConfig("bar")
+	config.Region = "eu-west-1"
+
+	if value, _ := awsProfile.GetConfigs().GetRegion("bar"); value != "eu-west-1" {
+		t.Fatal("a change of *Config is lost", value)
+	}
+
+	// the pointer is stable while profiles are added and replaced
+	for i := 0; i < 100; i++ {
+		awsProfile.Configs.Set(awsprofile.Config{ProfileName: "profile-" + strconv.Itoa(i)})
+	}
+	awsProfile.Configs.Set(awsprofile.Config{ProfileName: "bar", Region: "us-west-1"})
+
+	if config.Region != "us-west-1" {
+		t.Fatal("*Config does not point to the profile", config.Region)
+	}
+
+	_, credential := awsProfile.IsCredential("foo")
+	credential.AwsAccessKeyID = "ACCESS-NEW"
+
+	if value, _ := awsProfile.GetCredentials().GetAwsAccessKeyID("foo"); value != "ACCESS-NEW" {
+		t.Fatal("a change of *Credential is lost", value)
+	}
+}
+
+// newProfile parses the credentials and config files under dir/.aws.
+func newProfile(t *testing.T, dir string) *awsprofile.AwsProfile {
+	awsProfile := awsprofile.New(
+		awsprofile.WithCredentialsFile(dir+"/.aws/credentials"),
+		awsprofile.WithConfigFile(dir+"/.aws/config"),
+	)
+	if err := awsProfile.Parse(); err != nil {
+		t.Fatal(err)
+	}
+
+	return awsProfile
+}
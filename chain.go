@@ -0,0 +1,146 @@
+package awsprofile
+
+import (
+	"errors"
+	"strings"
+)
+
+// ChainKind is how a link of a chain gets credentials
+type ChainKind string
+
+// kinds of chain link
+const (
+	ChainAssumeRole        ChainKind = "assume_role"
+	ChainStaticCredentials ChainKind = "static_credentials"
+	ChainCredentialSource  ChainKind = "credential_source"
+	ChainCredentialProcess ChainKind = "credential_process"
+	ChainWebIdentity       ChainKind = "web_identity"
+	ChainSSO               ChainKind = "sso"
+)
+
+// chain errors
+var (
+	ErrorChainCycle         = errors.New("source_profile is cyclic")
+	ErrorChainDangling      = errors.New("source_profile refers to a profile that" + ErrorNotFound)
+	ErrorChainMixed         = errors.New("source_profile and credential_source are both set")
+	ErrorChainNoSource      = errors.New("source_profile or credential_source" + ErrorNotFound)
+	ErrorChainNoCredentials = errors.New("credentials" + ErrorNotFound)
+)
+
+// ChainLink is a profile in a chain
+type ChainLink struct {
+	*ResolvedProfile
+	Kind ChainKind
+}
+
+// ChainError is returned when a chain can not be followed
+type ChainError struct {
+	Profile string
+	Chain   []string
+	Err     error
+}
+
+func (e *ChainError) Error() string {
+	return e.Profile + ": " + e.Err.Error() + " (" + strings.Join(e.Chain, " -> ") + ")"
+}
+
+// Unwrap returns one of the chain errors
+func (e *ChainError) Unwrap() error {
+	return e.Err
+}
+
+// Chain follow source_profile from a profile until it reaches credentials.
+// The first link is the profile itself and the Kind of the last link tells where credentials come from.
+func (a *AwsProfile) Chain(profile string) ([]ChainLink, error) {
+	var chain []ChainLink
+	var names []string
+
+	snapshot := a.Snapshot()
+	visited := make(map[string]bool)
+	name := profile
+
+	for {
+		names = append(names, name)
+
+		resolved, err := snapshot.Resolve(name)
+		if err != nil {
+			if len(chain) == 0 || !errors.Is(err, ErrorNotFoundProfile) {
+				return nil, err
+			}
+
+			return nil, &ChainError{Profile: chain[len(chain)-1].ProfileName, Chain: names, Err: ErrorChainDangling}
+		}
+
+		kind, err := chainKind(resolved, len(chain) == 0)
+		if err != nil {
+			return nil, &ChainError{Profile: name, Chain: names, Err: err}
+		}
+
+		chain = append(chain, ChainLink{ResolvedProfile: resolved, Kind: kind})
+		visited[name] = true
+
+		if kind != ChainAssumeRole {
+			return chain, nil
+		}
+
+		next := resolved.SourceProfile
+		if visited[next] {
+			// A profile may source itself to use its own static credentials.
+			if next != name {
+				return nil, &ChainError{Profile: name, Chain: append(names, next), Err: ErrorChainCycle}
+			}
+
+			if !hasStaticCredentials(resolved) {
+				return nil, &ChainError{Profile: name, Chain: append(names, next), Err: ErrorChainCycle}
+			}
+
+			chain = append(chain, ChainLink{ResolvedProfile: resolved, Kind: ChainStaticCredentials})
+
+			return chain, nil
+		}
+
+		name = next
+	}
+}
+
+// chainKind decide how a profile gets credentials in the same order as the AWS CLI.
+// Static credentials of a source profile take precedence over its role_arn.
+func chainKind(profile *ResolvedProfile, first bool) (ChainKind, error) {
+	if profile.RoleArn != EmptyString && (first || !hasStaticCredentials(profile)) {
+		if profile.WebIdentityTokenFile != EmptyString {
+			return ChainWebIdentity, nil
+		}
+
+		if profile.SourceProfile != EmptyString && profile.CredentialSource != EmptyString {
+			return ChainKind(EmptyString), ErrorChainMixed
+		}
+
+		if profile.SourceProfile == EmptyString && profile.CredentialSource == EmptyString {
+			return ChainKind(EmptyString), ErrorChainNoSource
+		}
+
+		if profile.CredentialSource != EmptyString {
+			return ChainCredentialSource, nil
+		}
+
+		return ChainAssumeRole, nil
+	}
+
+	if profile.Session != nil || profile.SSOStartURL != EmptyString {
+		return ChainSSO, nil
+	}
+
+	if hasStaticCredentials(profile) {
+		return ChainStaticCredentials, nil
+	}
+
+	if profile.CredentialProcess != EmptyString {
+		return ChainCredentialProcess, nil
+	}
+
+	return ChainKind(EmptyString), ErrorChainNoCredentials
+}
+
+func hasStaticCredentials(profile *ResolvedProfile) bool {
+	return profile.AwsAccessKeyID != EmptyString && profile.AwsSecretAccessKey != EmptyString
+}
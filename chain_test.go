@@ -0,0 +1,111 @@
+package awsprofile_test
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"testing"
+
+	"github.com/youyo/awsprofile"
+)
+
+func ExampleAwsProfile_Chain() {
+	// if you use non-default configuration file path
+	if err := os.Setenv("AWS_SHARED_CREDENTIALS_FILE", "./tests/chain/.aws/credentials"); err != nil {
+		log.Fatal(err)
+	}
+	if err := os.Setenv("AWS_CONFIG_FILE", "./tests/chain/.aws/config"); err != nil {
+		log.Fatal(err)
+	}
+
+	awsProfile := awsprofile.New()
+	if err := awsProfile.Parse(); err != nil {
+		log.Fatal(err)
+	}
+
+	// Follow source_profile
+	chain, err := awsProfile.Chain("admin")
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	for _, link := range chain {
+		fmt.Println(link.ProfileName, link.Kind)
+	}
+	// Output:
+	// admin assume_role
+	// dev assume_role
+	// base static_credentials
+}
+
+func TestAwsProfile_Chain(t *testing.T) {
+	awsProfile := newProfile(t, "./tests/chain")
+
+	tests := []struct {
+		profile string
+		expect  string
+	}{
+		{"admin", "[admin:assume_role dev:assume_role base:static_credentials]"},
+		{"instance", "[instance:credential_source]"},
+		{"process", "[process:credential_process]"},
+		{"web", "[web:web_identity]"},
+		{"self", "[self:assume_role self:static_credentials]"},
+		{"base", "[base:static_credentials]"},
+	}
+
+	for _, tt := range tests {
+		chain, err := awsProfile.Chain(tt.profile)
+		if err != nil {
+			t.Fatal(tt.profile, err)
+		}
+
+		var links []string
+		for _, link := range chain {
+			links = append(links, link.ProfileName+":"+string(link.Kind))
+		}
+
+		if fmt.Sprint(links) != tt.expect {
+			t.Error(tt.profile, links, "expect", tt.expect)
+		}
+	}
+}
+
+func TestAwsProfile_Chain_Error(t *testing.T) {
+	awsProfile := newProfile(t, "./tests/chain")
+
+	tests := []struct {
+		profile string
+		expect  error
+	}{
+		{"cycle-a", awsprofile.ErrorChainCycle},
+		{"dangling", awsprofile.ErrorChainDangling},
+		{"mixed", awsprofile.ErrorChainMixed},
+		{"empty", awsprofile.ErrorChainNoCredentials},
+	}
+
+	for _, tt := range tests {
+		_, err := awsProfile.Chain(tt.profile)
+		if !errors.Is(err, tt.expect) {
+			t.Error(tt.profile, "Unexpected error", err)
+		}
+
+		var chainError *awsprofile.ChainError
+		if !errors.As(err, &chainError) {
+			t.Error(tt.profile, "error is not ChainError", err)
+		}
+	}
+
+	if _, err := awsProfile.Chain("fooooo"); !errors.Is(err, awsprofile.ErrorNotFoundProfile) {
+		t.Error("Unexpected error", err)
+	}
+}
+
+func TestChainError_Error(t *testing.T) {
+	awsProfile := newProfile(t, "./tests/chain")
+
+	_, err := awsProfile.Chain("cycle-a")
+	if err.Error() != "cycle-b: source_profile is cyclic (cycle-a -> cycle-b -> cycle-a)" {
+		t.Fatal(err)
+	}
+}
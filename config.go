@@ -1,172 +1,409 @@
 package awsprofile
 
 import (
+	"bytes"
 	"errors"
-	"os"
+	"io"
+	"sort"
+	"strconv"
 	"strings"
-
-	homedir "github.com/mitchellh/go-homedir"
-	ini "gopkg.in/ini.v1"
 )
 
 const (
-	AWS_CONFIG_FILE         string = "AWS_CONFIG_FILE"
-	AWS_CONFIG              string = "~/.aws/config"
-	ROLE_ARN                string = "role_arn"
-	SOURCE_PROFILE          string = "source_profile"
-	CREDENTIAL_SOURCE       string = "credential_source"
-	ROLE_SESSION_NAME       string = "role_session_name"
-	MFA_SERIAL              string = "mfa_serial"
-	DURATION_SECONDS        string = "duration_seconds"
-	AWS_SESSION_TOKEN       string = "aws_session_token"
-	EXTERNAL_ID             string = "external_id"
-	CA_BUNDLE               string = "ca_bundle"
-	CLI_FOLLOW_URLPARAM     string = "cli_follow_urlparam"
-	CLI_TIMESTAMP_FORMAT    string = "cli_timestamp_format"
-	CREDENTIAL_PROCESS      string = "credential_process"
-	WEB_IDENTITY_TOKEN_FILE string = "web_identity_token_file"
-	OUTPUT                  string = "output"
-	REGION                  string = "region"
+	AWS_CONFIG_FILE                    string = "AWS_CONFIG_FILE"
+	AWS_CONFIG                         string = "~/.aws/config"
+	ROLE_ARN                           string = "role_arn"
+	SOURCE_PROFILE                     string = "source_profile"
+	CREDENTIAL_SOURCE                  string = "credential_source"
+	ROLE_SESSION_NAME                  string = "role_session_name"
+	MFA_SERIAL                         string = "mfa_serial"
+	DURATION_SECONDS                   string = "duration_seconds"
+	AWS_SESSION_TOKEN                  string = "aws_session_token"
+	EXTERNAL_ID                        string = "external_id"
+	CA_BUNDLE                          string = "ca_bundle"
+	CLI_FOLLOW_URLPARAM                string = "cli_follow_urlparam"
+	CLI_TIMESTAMP_FORMAT               string = "cli_timestamp_format"
+	CREDENTIAL_PROCESS                 string = "credential_process"
+	WEB_IDENTITY_TOKEN_FILE            string = "web_identity_token_file"
+	OUTPUT                             string = "output"
+	REGION                             string = "region"
+	SSO_START_URL                      string = "sso_start_url"
+	SSO_REGION                         string = "sso_region"
+	SSO_ACCOUNT_ID                     string = "sso_account_id"
+	SSO_ROLE_NAME                      string = "sso_role_name"
+	SSO_SESSION                        string = "sso_session"
+	SERVICES                           string = "services"
+	ENDPOINT_URL                       string = "endpoint_url"
+	IGNORE_CONFIGURED_ENDPOINT_URLS    string = "ignore_configured_endpoint_urls"
+	RETRY_MODE                         string = "retry_mode"
+	MAX_ATTEMPTS                       string = "max_attempts"
+	DEFAULTS_MODE                      string = "defaults_mode"
+	USE_FIPS_ENDPOINT                  string = "use_fips_endpoint"
+	STS_REGIONAL_ENDPOINTS             string = "sts_regional_endpoints"
+	EC2_METADATA_SERVICE_ENDPOINT      string = "ec2_metadata_service_endpoint"
+	EC2_METADATA_SERVICE_ENDPOINT_MODE string = "ec2_metadata_service_endpoint_mode"
+	METADATA_SERVICE_TIMEOUT           string = "metadata_service_timeout"
+	METADATA_SERVICE_NUM_ATTEMPTS      string = "metadata_service_num_attempts"
+	CLI_PAGER                          string = "cli_pager"
+	CLI_AUTO_PROMPT                    string = "cli_auto_prompt"
+	PARAMETER_VALIDATION               string = "parameter_validation"
+	TCP_KEEPALIVE                      string = "tcp_keepalive"
+	SOURCE_IDENTITY                    string = "source_identity"
 )
 
 var (
-	ErrorNotFoundRoleArn              error = errors.New(ROLE_ARN + ErrorNotFound)
-	ErrorNotFoundSourceProfile        error = errors.New(SOURCE_PROFILE + ErrorNotFound)
-	ErrorNotFoundCredentialSource     error = errors.New(CREDENTIAL_SOURCE + ErrorNotFound)
-	ErrorNotFoundRoleSessionName      error = errors.New(ROLE_SESSION_NAME + ErrorNotFound)
-	ErrorNotFoundMfaSerial            error = errors.New(MFA_SERIAL + ErrorNotFound)
-	ErrorNotFoundDurationSeconds      error = errors.New(DURATION_SECONDS + ErrorNotFound)
-	ErrorNotFoundAwsSessionToken      error = errors.New(AWS_SESSION_TOKEN + ErrorNotFound)
-	ErrorNotFoundExternalID           error = errors.New(EXTERNAL_ID + ErrorNotFound)
-	ErrorNotFoundCaBundle             error = errors.New(CA_BUNDLE + ErrorNotFound)
-	ErrorNotFoundCliFollowUrlparam    error = errors.New(CLI_FOLLOW_URLPARAM + ErrorNotFound)
-	ErrorNotFoundCliTimestampFormat   error = errors.New(CLI_TIMESTAMP_FORMAT + ErrorNotFound)
-	ErrorNotFoundCredentialProcess    error = errors.New(CREDENTIAL_PROCESS + ErrorNotFound)
-	ErrorNotFoundWebIdentityTokenFile error = errors.New(WEB_IDENTITY_TOKEN_FILE + ErrorNotFound)
-	ErrorNotFoundOutput               error = errors.New(OUTPUT + ErrorNotFound)
-	ErrorNotFoundRegion               error = errors.New(REGION + ErrorNotFound)
+	ErrorNotFoundRoleArn                        error = errors.New(ROLE_ARN + ErrorNotFound)
+	ErrorNotFoundSourceProfile                  error = errors.New(SOURCE_PROFILE + ErrorNotFound)
+	ErrorNotFoundCredentialSource               error = errors.New(CREDENTIAL_SOURCE + ErrorNotFound)
+	ErrorNotFoundRoleSessionName                error = errors.New(ROLE_SESSION_NAME + ErrorNotFound)
+	ErrorNotFoundMfaSerial                      error = errors.New(MFA_SERIAL + ErrorNotFound)
+	ErrorNotFoundDurationSeconds                error = errors.New(DURATION_SECONDS + ErrorNotFound)
+	ErrorNotFoundAwsSessionToken                error = errors.New(AWS_SESSION_TOKEN + ErrorNotFound)
+	ErrorNotFoundExternalID                     error = errors.New(EXTERNAL_ID + ErrorNotFound)
+	ErrorNotFoundCaBundle                       error = errors.New(CA_BUNDLE + ErrorNotFound)
+	ErrorNotFoundCliFollowUrlparam              error = errors.New(CLI_FOLLOW_URLPARAM + ErrorNotFound)
+	ErrorNotFoundCliTimestampFormat             error = errors.New(CLI_TIMESTAMP_FORMAT + ErrorNotFound)
+	ErrorNotFoundCredentialProcess              error = errors.New(CREDENTIAL_PROCESS + ErrorNotFound)
+	ErrorNotFoundWebIdentityTokenFile           error = errors.New(WEB_IDENTITY_TOKEN_FILE + ErrorNotFound)
+	ErrorNotFoundOutput                         error = errors.New(OUTPUT + ErrorNotFound)
+	ErrorNotFoundRegion                         error = errors.New(REGION + ErrorNotFound)
+	ErrorNotFoundSSOStartURL                    error = errors.New(SSO_START_URL + ErrorNotFound)
+	ErrorNotFoundSSORegion                      error = errors.New(SSO_REGION + ErrorNotFound)
+	ErrorNotFoundSSOAccountID                   error = errors.New(SSO_ACCOUNT_ID + ErrorNotFound)
+	ErrorNotFoundSSORoleName                    error = errors.New(SSO_ROLE_NAME + ErrorNotFound)
+	ErrorNotFoundSSOSession                     error = errors.New(SSO_SESSION + ErrorNotFound)
+	ErrorNotFoundServices                       error = errors.New(SERVICES + ErrorNotFound)
+	ErrorNotFoundEndpointURL                    error = errors.New(ENDPOINT_URL + ErrorNotFound)
+	ErrorNotFoundIgnoreConfiguredEndpointURLs   error = errors.New(IGNORE_CONFIGURED_ENDPOINT_URLS + ErrorNotFound)
+	ErrorNotFoundRetryMode                      error = errors.New(RETRY_MODE + ErrorNotFound)
+	ErrorNotFoundMaxAttempts                    error = errors.New(MAX_ATTEMPTS + ErrorNotFound)
+	ErrorNotFoundDefaultsMode                   error = errors.New(DEFAULTS_MODE + ErrorNotFound)
+	ErrorNotFoundUseFIPSEndpoint                error = errors.New(USE_FIPS_ENDPOINT + ErrorNotFound)
+	ErrorNotFoundUseDualstackEndpoint           error = errors.New(USE_DUALSTACK_ENDPOINT + ErrorNotFound)
+	ErrorNotFoundSTSRegionalEndpoints           error = errors.New(STS_REGIONAL_ENDPOINTS + ErrorNotFound)
+	ErrorNotFoundEC2MetadataServiceEndpoint     error = errors.New(EC2_METADATA_SERVICE_ENDPOINT + ErrorNotFound)
+	ErrorNotFoundEC2MetadataServiceEndpointMode error = errors.New(EC2_METADATA_SERVICE_ENDPOINT_MODE + ErrorNotFound)
+	ErrorNotFoundMetadataServiceTimeout         error = errors.New(METADATA_SERVICE_TIMEOUT + ErrorNotFound)
+	ErrorNotFoundMetadataServiceNumAttempts     error = errors.New(METADATA_SERVICE_NUM_ATTEMPTS + ErrorNotFound)
+	ErrorNotFoundCliPager                       error = errors.New(CLI_PAGER + ErrorNotFound)
+	ErrorNotFoundCliAutoPrompt                  error = errors.New(CLI_AUTO_PROMPT + ErrorNotFound)
+	ErrorNotFoundParameterValidation            error = errors.New(PARAMETER_VALIDATION + ErrorNotFound)
+	ErrorNotFoundTCPKeepalive                   error = errors.New(TCP_KEEPALIVE + ErrorNotFound)
+	ErrorNotFoundSourceIdentity                 error = errors.New(SOURCE_IDENTITY + ErrorNotFound)
 )
 
 type Config struct {
-	ProfileName          string
-	RoleArn              string
-	SourceProfile        string
-	CredentialSource     string
-	RoleSessionName      string
-	MfaSerial            string
-	DurationSeconds      int
-	AwsSessionToken      string
-	ExternalID           int
-	CaBundle             string
-	CliFollowUrlparam    string
-	CliTimestampFormat   string
-	CredentialProcess    string
-	WebIdentityTokenFile string
-	Output               string
-	Region               string
-}
-
-type Configs []Config
+	ProfileName                    string
+	AwsAccessKeyID                 string
+	AwsSecretAccessKey             string
+	RoleArn                        string
+	SourceProfile                  string
+	CredentialSource               string
+	RoleSessionName                string
+	MfaSerial                      string
+	DurationSeconds                int
+	AwsSessionToken                string
+	ExternalID                     string
+	CaBundle                       string
+	CliFollowUrlparam              string
+	CliTimestampFormat             string
+	CredentialProcess              string
+	WebIdentityTokenFile           string
+	Output                         string
+	Region                         string
+	SSOStartURL                    string
+	SSORegion                      string
+	SSOAccountID                   string
+	SSORoleName                    string
+	SSOSession                     string
+	Services                       string
+	EndpointURL                    string
+	IgnoreConfiguredEndpointURLs   bool
+	RetryMode                      string
+	MaxAttempts                    int
+	DefaultsMode                   string
+	UseFIPSEndpoint                bool
+	UseDualstackEndpoint           bool
+	STSRegionalEndpoints           string
+	EC2MetadataServiceEndpoint     string
+	EC2MetadataServiceEndpointMode string
+	MetadataServiceTimeout         int
+	MetadataServiceNumAttempts     int
+	CliPager                       string
+	CliAutoPrompt                  string
+	ParameterValidation            bool
+	TCPKeepalive                   bool
+	SourceIdentity                 string
+	S3                             S3Settings
+	S3API                          S3Settings
+	Nested                         map[string]map[string]string
+	Extra                          map[string]string
+	present                        map[string]bool
+	written                        map[string]string
+	origins                        origins
+}
+
+// Configs has many Config in the order of config file.
+// Configs are indexed by profile name, so a lookup does not depend on the number of profiles.
+type Configs struct {
+	list  []*Config
+	index map[string]int
+
+	// skipped are profiles which ParseLenient skipped, whose sections Save keeps as they are
+	skipped map[string]bool
+}
 
 func NewConfigs() *Configs {
 	return new(Configs)
 }
 
+// Parse config file, and replace configs with the profiles of config file.
+// It stops at the first invalid line or value, and returns it as *ParseError without changing configs.
 func (c *Configs) Parse(configFile string) error {
-	data, err := ini.Load(configFile)
+	return parseFile(configFile, false, c.parse)
+}
+
+// ParseLenient parse config file like Parse, but it does not stop at invalid lines and values.
+// Profiles which have no errors are all parsed, and errors are returned as ParseErrors.
+func (c *Configs) ParseLenient(configFile string) error {
+	return parseFile(configFile, true, c.parse)
+}
+
+// ParseBytes parse data like Parse.
+// source is used as the path of *ParseError, e.g. the name of a secret, and can be empty.
+func (c *Configs) ParseBytes(data []byte, source string) error {
+	return c.parse(data, source, false)
+}
+
+// ParseReader read r to the end and parse it like ParseBytes
+func (c *Configs) ParseReader(r io.Reader, source string) error {
+	return parseReader(r, source, false, c.parse)
+}
+
+func (c *Configs) parse(data []byte, source string, lenient bool) error {
+	d, errs, err := parseSource(data, source, lenient)
 	if err != nil {
 		return err
 	}
 
-	for _, section := range data.Sections() {
-		if section.Name() == "DEFAULT" {
+	parsed := Configs{}
+
+	for _, section := range d.sections {
+		profileName, ok := configProfileName(section)
+		if !ok {
 			continue
 		}
 
 		config := Config{}
 
-		config.ProfileName = strings.Replace(section.Name(), "profile ", EmptyString, 1)
+		config.ProfileName = profileName
 
-		if section.HasKey(ROLE_ARN) {
-			config.RoleArn = section.Key(ROLE_ARN).String()
-		}
+		var sectionErrs ParseErrors
 
-		if section.HasKey(SOURCE_PROFILE) {
-			config.SourceProfile = section.Key(SOURCE_PROFILE).String()
-		}
+		for _, kv := range section.keyValues() {
+			config.origins.set(kv.key, Origin{Path: source, Line: kv.number, Section: section.name})
 
-		if section.HasKey(CREDENTIAL_SOURCE) {
-			config.CredentialSource = section.Key(CREDENTIAL_SOURCE).String()
+			if kv.nested == nil {
+				if err := config.setValue(kv.key, kv.value); err != nil {
+					sectionErrs = append(sectionErrs, &ParseError{Path: source, Line: kv.number, Profile: profileName, Key: kv.key, Err: err})
+				}
+				continue
+			}
+
+			for _, nested := range kv.nested {
+				config.origins.set(kv.key+"."+nested.key, Origin{Path: source, Line: nested.number, Section: section.name})
+
+				if err := config.setNested(kv.key, nested); err != nil {
+					sectionErrs = append(sectionErrs, &ParseError{Path: source, Line: nested.number, Profile: profileName, Key: kv.key + "." + nested.key, Err: err})
+				}
+			}
 		}
 
-		if section.HasKey(ROLE_SESSION_NAME) {
-			config.RoleSessionName = section.Key(ROLE_SESSION_NAME).String()
+		if sectionErrs != nil && !lenient {
+			return sectionErrs[0]
 		}
 
-		if section.HasKey(MFA_SERIAL) {
-			config.MfaSerial = section.Key(MFA_SERIAL).String()
+		// a profile which has errors is not parsed cleanly
+		if sectionErrs != nil || section.invalid() {
+			errs = append(errs, sectionErrs...)
+			parsed.skipped = skipProfile(parsed.skipped, profileName)
+			continue
 		}
 
-		if section.HasKey(DURATION_SECONDS) {
-			durationSeconds, err := section.Key(DURATION_SECONDS).Int()
-			if err != nil {
-				return err
-			}
+		parsed.Set(config)
+	}
 
-			config.DurationSeconds = durationSeconds
-		}
+	*c = parsed
 
-		if section.HasKey(AWS_SESSION_TOKEN) {
-			config.AwsSessionToken = section.Key(AWS_SESSION_TOKEN).String()
-		}
+	errs.sortByLine()
 
-		if section.HasKey(EXTERNAL_ID) {
-			externalID, err := section.Key(EXTERNAL_ID).Int()
-			if err != nil {
-				return err
-			}
+	return errs.err()
+}
 
-			config.ExternalID = externalID
-		}
+// Set add a config, or replace the config of the same profile.
+// Like the AWS CLI, when both [default] and [profile default] exist the later section wins.
+// A replaced config is updated in place, so *Config returned by IsConfig keeps pointing to the profile.
+func (c *Configs) Set(config Config) {
+	if i, ok := c.index[config.ProfileName]; ok {
+		*c.list[i] = config
+		return
+	}
 
-		if section.HasKey(CA_BUNDLE) {
-			config.CaBundle = section.Key(CA_BUNDLE).String()
-		}
+	if c.index == nil {
+		c.index = make(map[string]int)
+	}
+
+	c.index[config.ProfileName] = len(c.list)
+	c.list = append(c.list, &config)
+}
 
-		if section.HasKey(CLI_FOLLOW_URLPARAM) {
-			config.CliFollowUrlparam = section.Key(CLI_FOLLOW_URLPARAM).String()
+// Delete remove a config. A profile which ParseLenient skipped is removed by Save too.
+func (c *Configs) Delete(profileName string) bool {
+	i, ok := c.index[profileName]
+	if !ok {
+		if c.skipped[profileName] {
+			delete(c.skipped, profileName)
+			return true
 		}
 
-		if section.HasKey(CLI_TIMESTAMP_FORMAT) {
-			config.CliTimestampFormat = section.Key(CLI_TIMESTAMP_FORMAT).String()
+		return false
+	}
+
+	c.list = append(c.list[:i], c.list[i+1:]...)
+
+	delete(c.index, profileName)
+	for j := i; j < len(c.list); j++ {
+		c.index[c.list[j].ProfileName] = j
+	}
+
+	return true
+}
+
+// List get configs in the order of config file
+func (c *Configs) List() []Config {
+	var list []Config
+	for _, config := range c.list {
+		list = append(list, *config)
+	}
+
+	return list
+}
+
+// Len get the number of configs
+func (c *Configs) Len() int {
+	return len(c.list)
+}
+
+// WriteTo write configs in config file format
+func (c *Configs) WriteTo(w io.Writer) (int64, error) {
+	d := newDocument()
+	if err := c.patch(d); err != nil {
+		return 0, err
+	}
+
+	return bytes.NewBuffer(d.Bytes()).WriteTo(w)
+}
+
+// Save write config file atomically.
+// If configFile exists, only the lines of changed keys are rewritten,
+// and comments, blank lines, order and unknown keys are kept as they are.
+// Sections of profiles which ParseLenient skipped are kept as they are.
+// Names, keys and values which cannot be parsed back as they are, such as a profile name with ], are errors.
+func (c *Configs) Save(configFile string) error {
+	d, err := loadDocument(configFile, c.skipped != nil)
+	if err != nil {
+		return err
+	}
+
+	if err := c.patch(d); err != nil {
+		return err
+	}
+
+	return writeFileAtomic(configFile, d.Bytes())
+}
+
+// patch make profile sections of d the same as c.
+// When a profile has more than one section, the last one which the AWS CLI reads is updated.
+// Sections of profiles which ParseLenient skipped are kept as they are.
+func (c *Configs) patch(d *document) error {
+	sections := make(map[string]*section)
+
+	for _, section := range append([]*section(nil), d.sections...) {
+		profileName, ok := configProfileName(section)
+		if !ok {
+			continue
 		}
 
-		if section.HasKey(CREDENTIAL_PROCESS) {
-			config.CredentialProcess = section.Key(CREDENTIAL_PROCESS).String()
+		if _, ok := c.get(profileName); !ok {
+			if !c.skipped[profileName] {
+				d.removeSection(section)
+			}
+			continue
 		}
 
-		if section.HasKey(WEB_IDENTITY_TOKEN_FILE) {
-			config.WebIdentityTokenFile = section.Key(WEB_IDENTITY_TOKEN_FILE).String()
+		sections[profileName] = section
+	}
+
+	for _, config := range c.list {
+		kvs := config.keyValues()
+		if err := checkSection(config.ProfileName, kvs); err != nil {
+			return err
 		}
 
-		if section.HasKey(OUTPUT) {
-			config.Output = section.Key(OUTPUT).String()
+		section, ok := sections[config.ProfileName]
+		if !ok {
+			section = d.addSection(configSectionName(config.ProfileName))
 		}
 
-		if section.HasKey(REGION) {
-			config.Region = section.Key(REGION).String()
+		// every key of the section is kept in Config, in a field, Nested or Extra
+		known := append([]string(nil), configKeys...)
+		for _, kv := range section.keyValues() {
+			known = append(known, kv.key)
 		}
 
-		*c = append(*c, config)
+		section.apply(kvs, known)
 	}
 
 	return nil
 }
 
+// configProfileName returns the profile name of a section.
+// Like botocore, only [default] and [profile name] are profiles, and both [default] and [profile default] are the default profile.
+// Other sections such as [preview] and [plugins] are kept as they are.
+func configProfileName(section *section) (string, bool) {
+	if section.header == nil {
+		return EmptyString, false
+	}
+
+	if section.name == DEFAULT_PROFILE {
+		return section.name, true
+	}
+
+	return prefixedSectionName(section, "profile")
+}
+
+// prefixedSectionName returns name of [prefix name] section
+func prefixedSectionName(section *section, prefix string) (string, bool) {
+	if section.header == nil || !strings.HasPrefix(section.name, prefix+" ") {
+		return EmptyString, false
+	}
+
+	return strings.TrimSpace(strings.TrimPrefix(section.name, prefix+" ")), true
+}
+
+// configSectionName returns [default] for the default profile and [profile name] for others
+func configSectionName(profileName string) string {
+	if profileName == "default" {
+		return profileName
+	}
+
+	return "profile " + profileName
+}
+
 func (c *Configs) ProfileNames() ([]string, error) {
 	var profileNames []string
 
-	for _, config := range *c {
+	for _, config := range c.list {
 		profileNames = append(profileNames, config.ProfileName)
 	}
 
@@ -178,7 +415,7 @@ func (c *Configs) GetRoleArn(profileName string) (string, error) {
 		return config.RoleArn, nil
 	}
 
-	return EmptyString, ErrorNotFoundRoleArn
+	return EmptyString, c.notFound(profileName, ErrorNotFoundRoleArn)
 }
 
 func (c *Configs) GetSourceProfile(profileName string) (string, error) {
@@ -186,7 +423,7 @@ func (c *Configs) GetSourceProfile(profileName string) (string, error) {
 		return config.SourceProfile, nil
 	}
 
-	return EmptyString, ErrorNotFoundSourceProfile
+	return EmptyString, c.notFound(profileName, ErrorNotFoundSourceProfile)
 }
 
 func (c *Configs) GetCredentialSource(profileName string) (string, error) {
@@ -194,7 +431,7 @@ func (c *Configs) GetCredentialSource(profileName string) (string, error) {
 		return config.CredentialSource, nil
 	}
 
-	return EmptyString, ErrorNotFoundCredentialSource
+	return EmptyString, c.notFound(profileName, ErrorNotFoundCredentialSource)
 }
 
 func (c *Configs) GetRoleSessionName(profileName string) (string, error) {
@@ -202,7 +439,7 @@ func (c *Configs) GetRoleSessionName(profileName string) (string, error) {
 		return config.RoleSessionName, nil
 	}
 
-	return EmptyString, ErrorNotFoundRoleSessionName
+	return EmptyString, c.notFound(profileName, ErrorNotFoundRoleSessionName)
 }
 
 func (c *Configs) GetMfaSerial(profileName string) (string, error) {
@@ -210,7 +447,7 @@ func (c *Configs) GetMfaSerial(profileName string) (string, error) {
 		return config.MfaSerial, nil
 	}
 
-	return EmptyString, ErrorNotFoundMfaSerial
+	return EmptyString, c.notFound(profileName, ErrorNotFoundMfaSerial)
 }
 
 func (c *Configs) GetDurationSeconds(profileName string) (int, error) {
@@ -218,7 +455,7 @@ func (c *Configs) GetDurationSeconds(profileName string) (int, error) {
 		return config.DurationSeconds, nil
 	}
 
-	return ZeroInt, ErrorNotFoundDurationSeconds
+	return ZeroInt, c.notFound(profileName, ErrorNotFoundDurationSeconds)
 }
 
 func (c *Configs) GetAwsSessionToken(profileName string) (string, error) {
@@ -226,15 +463,27 @@ func (c *Configs) GetAwsSessionToken(profileName string) (string, error) {
 		return config.AwsSessionToken, nil
 	}
 
-	return EmptyString, ErrorNotFoundAwsSessionToken
+	return EmptyString, c.notFound(profileName, ErrorNotFoundAwsSessionToken)
 }
 
-func (c *Configs) GetExternalID(profileName string) (int, error) {
+// GetExternalID get external_id, which is an opaque string such as a UUID
+func (c *Configs) GetExternalID(profileName string) (string, error) {
 	if config, ok := c.get(profileName); ok {
 		return config.ExternalID, nil
 	}
 
-	return ZeroInt, ErrorNotFoundExternalID
+	return EmptyString, c.notFound(profileName, ErrorNotFoundExternalID)
+}
+
+// GetExternalIDInt get external_id as int.
+//
+// Deprecated: external_id is not always a number. Use GetExternalID instead.
+func (c *Configs) GetExternalIDInt(profileName string) (int, error) {
+	if config, ok := c.get(profileName); ok {
+		return config.GetExternalIDInt()
+	}
+
+	return ZeroInt, c.notFound(profileName, ErrorNotFoundExternalID)
 }
 
 func (c *Configs) GetCaBundle(profileName string) (string, error) {
@@ -242,7 +491,7 @@ func (c *Configs) GetCaBundle(profileName string) (string, error) {
 		return config.CaBundle, nil
 	}
 
-	return EmptyString, ErrorNotFoundCaBundle
+	return EmptyString, c.notFound(profileName, ErrorNotFoundCaBundle)
 }
 
 func (c *Configs) GetCliFollowUrlparam(profileName string) (string, error) {
@@ -250,7 +499,7 @@ func (c *Configs) GetCliFollowUrlparam(profileName string) (string, error) {
 		return config.CliFollowUrlparam, nil
 	}
 
-	return EmptyString, ErrorNotFoundCliFollowUrlparam
+	return EmptyString, c.notFound(profileName, ErrorNotFoundCliFollowUrlparam)
 }
 
 func (c *Configs) GetCliTimestampFormat(profileName string) (string, error) {
@@ -258,7 +507,7 @@ func (c *Configs) GetCliTimestampFormat(profileName string) (string, error) {
 		return config.CliTimestampFormat, nil
 	}
 
-	return EmptyString, ErrorNotFoundCliTimestampFormat
+	return EmptyString, c.notFound(profileName, ErrorNotFoundCliTimestampFormat)
 }
 
 func (c *Configs) GetCredentialProcess(profileName string) (string, error) {
@@ -266,7 +515,7 @@ func (c *Configs) GetCredentialProcess(profileName string) (string, error) {
 		return config.CredentialProcess, nil
 	}
 
-	return EmptyString, ErrorNotFoundCredentialProcess
+	return EmptyString, c.notFound(profileName, ErrorNotFoundCredentialProcess)
 }
 
 func (c *Configs) GetWebIdentityTokenFile(profileName string) (string, error) {
@@ -274,7 +523,7 @@ func (c *Configs) GetWebIdentityTokenFile(profileName string) (string, error) {
 		return config.WebIdentityTokenFile, nil
 	}
 
-	return EmptyString, ErrorNotFoundWebIdentityTokenFile
+	return EmptyString, c.notFound(profileName, ErrorNotFoundWebIdentityTokenFile)
 }
 
 func (c *Configs) GetOutput(profileName string) (string, error) {
@@ -282,7 +531,7 @@ func (c *Configs) GetOutput(profileName string) (string, error) {
 		return config.Output, nil
 	}
 
-	return EmptyString, ErrorNotFoundOutput
+	return EmptyString, c.notFound(profileName, ErrorNotFoundOutput)
 }
 
 func (c *Configs) GetRegion(profileName string) (string, error) {
@@ -290,17 +539,546 @@ func (c *Configs) GetRegion(profileName string) (string, error) {
 		return config.Region, nil
 	}
 
-	return EmptyString, ErrorNotFoundRegion
+	return EmptyString, c.notFound(profileName, ErrorNotFoundRegion)
+}
+
+func (c *Configs) GetSSOStartURL(profileName string) (string, error) {
+	if config, ok := c.get(profileName); ok {
+		return config.SSOStartURL, nil
+	}
+
+	return EmptyString, c.notFound(profileName, ErrorNotFoundSSOStartURL)
+}
+
+func (c *Configs) GetSSORegion(profileName string) (string, error) {
+	if config, ok := c.get(profileName); ok {
+		return config.SSORegion, nil
+	}
+
+	return EmptyString, c.notFound(profileName, ErrorNotFoundSSORegion)
+}
+
+func (c *Configs) GetSSOAccountID(profileName string) (string, error) {
+	if config, ok := c.get(profileName); ok {
+		return config.SSOAccountID, nil
+	}
+
+	return EmptyString, c.notFound(profileName, ErrorNotFoundSSOAccountID)
+}
+
+func (c *Configs) GetSSORoleName(profileName string) (string, error) {
+	if config, ok := c.get(profileName); ok {
+		return config.SSORoleName, nil
+	}
+
+	return EmptyString, c.notFound(profileName, ErrorNotFoundSSORoleName)
+}
+
+func (c *Configs) GetSSOSession(profileName string) (string, error) {
+	if config, ok := c.get(profileName); ok {
+		return config.SSOSession, nil
+	}
+
+	return EmptyString, c.notFound(profileName, ErrorNotFoundSSOSession)
+}
+
+func (c *Configs) GetServices(profileName string) (string, error) {
+	if config, ok := c.get(profileName); ok {
+		return config.Services, nil
+	}
+
+	return EmptyString, c.notFound(profileName, ErrorNotFoundServices)
+}
+
+func (c *Configs) GetEndpointURL(profileName string) (string, error) {
+	if config, ok := c.get(profileName); ok {
+		return config.EndpointURL, nil
+	}
+
+	return EmptyString, c.notFound(profileName, ErrorNotFoundEndpointURL)
+}
+
+func (c *Configs) GetIgnoreConfiguredEndpointURLs(profileName string) (bool, error) {
+	if config, ok := c.get(profileName); ok {
+		return config.IgnoreConfiguredEndpointURLs, nil
+	}
+
+	return false, c.notFound(profileName, ErrorNotFoundIgnoreConfiguredEndpointURLs)
+}
+
+func (c *Configs) GetS3(profileName string) (S3Settings, error) {
+	if config, ok := c.get(profileName); ok {
+		return config.S3, nil
+	}
+
+	return S3Settings{}, c.notFound(profileName, ErrorNotFoundS3)
+}
+
+func (c *Configs) GetS3API(profileName string) (S3Settings, error) {
+	if config, ok := c.get(profileName); ok {
+		return config.S3API, nil
+	}
+
+	return S3Settings{}, c.notFound(profileName, ErrorNotFoundS3API)
+}
+
+// GetNested get nested values of key which have no field, e.g. GetNested("foo", "s3")
+func (c *Configs) GetNested(profileName, key string) (map[string]string, error) {
+	if config, ok := c.get(profileName); ok {
+		return config.Nested[key], nil
+	}
+
+	return nil, c.notFound(profileName, ErrorNotFoundProfile)
+}
+
+func (c *Configs) GetRetryMode(profileName string) (string, error) {
+	if config, ok := c.get(profileName); ok {
+		return config.RetryMode, nil
+	}
+
+	return EmptyString, c.notFound(profileName, ErrorNotFoundRetryMode)
+}
+
+func (c *Configs) GetMaxAttempts(profileName string) (int, error) {
+	if config, ok := c.get(profileName); ok {
+		return config.MaxAttempts, nil
+	}
+
+	return ZeroInt, c.notFound(profileName, ErrorNotFoundMaxAttempts)
+}
+
+func (c *Configs) GetDefaultsMode(profileName string) (string, error) {
+	if config, ok := c.get(profileName); ok {
+		return config.DefaultsMode, nil
+	}
+
+	return EmptyString, c.notFound(profileName, ErrorNotFoundDefaultsMode)
+}
+
+func (c *Configs) GetUseFIPSEndpoint(profileName string) (bool, error) {
+	if config, ok := c.get(profileName); ok {
+		return config.UseFIPSEndpoint, nil
+	}
+
+	return false, c.notFound(profileName, ErrorNotFoundUseFIPSEndpoint)
+}
+
+func (c *Configs) GetUseDualstackEndpoint(profileName string) (bool, error) {
+	if config, ok := c.get(profileName); ok {
+		return config.UseDualstackEndpoint, nil
+	}
+
+	return false, c.notFound(profileName, ErrorNotFoundUseDualstackEndpoint)
+}
+
+func (c *Configs) GetSTSRegionalEndpoints(profileName string) (string, error) {
+	if config, ok := c.get(profileName); ok {
+		return config.STSRegionalEndpoints, nil
+	}
+
+	return EmptyString, c.notFound(profileName, ErrorNotFoundSTSRegionalEndpoints)
+}
+
+func (c *Configs) GetEC2MetadataServiceEndpoint(profileName string) (string, error) {
+	if config, ok := c.get(profileName); ok {
+		return config.EC2MetadataServiceEndpoint, nil
+	}
+
+	return EmptyString, c.notFound(profileName, ErrorNotFoundEC2MetadataServiceEndpoint)
+}
+
+func (c *Configs) GetEC2MetadataServiceEndpointMode(profileName string) (string, error) {
+	if config, ok := c.get(profileName); ok {
+		return config.EC2MetadataServiceEndpointMode, nil
+	}
+
+	return EmptyString, c.notFound(profileName, ErrorNotFoundEC2MetadataServiceEndpointMode)
+}
+
+func (c *Configs) GetMetadataServiceTimeout(profileName string) (int, error) {
+	if config, ok := c.get(profileName); ok {
+		return config.MetadataServiceTimeout, nil
+	}
+
+	return ZeroInt, c.notFound(profileName, ErrorNotFoundMetadataServiceTimeout)
+}
+
+func (c *Configs) GetMetadataServiceNumAttempts(profileName string) (int, error) {
+	if config, ok := c.get(profileName); ok {
+		return config.MetadataServiceNumAttempts, nil
+	}
+
+	return ZeroInt, c.notFound(profileName, ErrorNotFoundMetadataServiceNumAttempts)
+}
+
+func (c *Configs) GetCliPager(profileName string) (string, error) {
+	if config, ok := c.get(profileName); ok {
+		return config.CliPager, nil
+	}
+
+	return EmptyString, c.notFound(profileName, ErrorNotFoundCliPager)
+}
+
+func (c *Configs) GetCliAutoPrompt(profileName string) (string, error) {
+	if config, ok := c.get(profileName); ok {
+		return config.CliAutoPrompt, nil
+	}
+
+	return EmptyString, c.notFound(profileName, ErrorNotFoundCliAutoPrompt)
+}
+
+func (c *Configs) GetParameterValidation(profileName string) (bool, error) {
+	if config, ok := c.get(profileName); ok {
+		return config.ParameterValidation, nil
+	}
+
+	return false, c.notFound(profileName, ErrorNotFoundParameterValidation)
+}
+
+func (c *Configs) GetTCPKeepalive(profileName string) (bool, error) {
+	if config, ok := c.get(profileName); ok {
+		return config.TCPKeepalive, nil
+	}
+
+	return false, c.notFound(profileName, ErrorNotFoundTCPKeepalive)
+}
+
+func (c *Configs) GetSourceIdentity(profileName string) (string, error) {
+	if config, ok := c.get(profileName); ok {
+		return config.SourceIdentity, nil
+	}
+
+	return EmptyString, c.notFound(profileName, ErrorNotFoundSourceIdentity)
+}
+
+// notFound returns NotFoundError of a profile which is not in c
+func (c *Configs) notFound(profileName string, err error) error {
+	profileNames, _ := c.ProfileNames()
+	return profileNotFound(profileName, profileNames, err)
 }
 
 func (c *Configs) get(profileName string) (*Config, bool) {
-	for _, config := range *c {
-		if config.ProfileName == profileName {
-			return &config, true
+	i, ok := c.index[profileName]
+	if !ok {
+		return nil, false
+	}
+
+	return c.list[i], true
+}
+
+// configKeys are keys of Config in the order of writing
+var configKeys = []string{
+	AwsAccessKeyID,
+	AwsSecretAccessKey,
+	ROLE_ARN,
+	SOURCE_PROFILE,
+	CREDENTIAL_SOURCE,
+	ROLE_SESSION_NAME,
+	MFA_SERIAL,
+	DURATION_SECONDS,
+	AWS_SESSION_TOKEN,
+	EXTERNAL_ID,
+	CA_BUNDLE,
+	CLI_FOLLOW_URLPARAM,
+	CLI_TIMESTAMP_FORMAT,
+	CREDENTIAL_PROCESS,
+	WEB_IDENTITY_TOKEN_FILE,
+	OUTPUT,
+	REGION,
+	SSO_START_URL,
+	SSO_REGION,
+	SSO_ACCOUNT_ID,
+	SSO_ROLE_NAME,
+	SSO_SESSION,
+	SERVICES,
+	ENDPOINT_URL,
+	IGNORE_CONFIGURED_ENDPOINT_URLS,
+	RETRY_MODE,
+	MAX_ATTEMPTS,
+	DEFAULTS_MODE,
+	USE_FIPS_ENDPOINT,
+	USE_DUALSTACK_ENDPOINT,
+	STS_REGIONAL_ENDPOINTS,
+	EC2_METADATA_SERVICE_ENDPOINT,
+	EC2_METADATA_SERVICE_ENDPOINT_MODE,
+	METADATA_SERVICE_TIMEOUT,
+	METADATA_SERVICE_NUM_ATTEMPTS,
+	CLI_PAGER,
+	CLI_AUTO_PROMPT,
+	PARAMETER_VALIDATION,
+	TCP_KEEPALIVE,
+	SOURCE_IDENTITY,
+}
+
+func isConfigKey(key string) bool {
+	for _, configKey := range configKeys {
+		if configKey == key {
+			return true
+		}
+	}
+
+	return false
+}
+
+// setValue set a value of the config file. Unknown keys are kept in Extra.
+// A key with an empty value such as "cli_pager =" has no value to parse,
+// so it is kept in Extra as it is.
+func (c *Config) setValue(key, value string) error {
+	var err error
+
+	if value == EmptyString {
+		setExtra(&c.Extra, key, value)
+		return nil
+	}
+
+	switch key {
+	case AwsAccessKeyID:
+		c.AwsAccessKeyID = value
+	case AwsSecretAccessKey:
+		c.AwsSecretAccessKey = value
+	case ROLE_ARN:
+		c.RoleArn = value
+	case SOURCE_PROFILE:
+		c.SourceProfile = value
+	case CREDENTIAL_SOURCE:
+		c.CredentialSource = value
+	case ROLE_SESSION_NAME:
+		c.RoleSessionName = value
+	case MFA_SERIAL:
+		c.MfaSerial = value
+	case DURATION_SECONDS:
+		c.DurationSeconds, err = strconv.Atoi(value)
+	case AWS_SESSION_TOKEN:
+		c.AwsSessionToken = value
+	case EXTERNAL_ID:
+		c.ExternalID = value
+	case CA_BUNDLE:
+		c.CaBundle = value
+	case CLI_FOLLOW_URLPARAM:
+		c.CliFollowUrlparam = value
+	case CLI_TIMESTAMP_FORMAT:
+		c.CliTimestampFormat = value
+	case CREDENTIAL_PROCESS:
+		c.CredentialProcess = value
+	case WEB_IDENTITY_TOKEN_FILE:
+		c.WebIdentityTokenFile = value
+	case OUTPUT:
+		c.Output = value
+	case REGION:
+		c.Region = value
+	case SSO_START_URL:
+		c.SSOStartURL = value
+	case SSO_REGION:
+		c.SSORegion = value
+	case SSO_ACCOUNT_ID:
+		c.SSOAccountID = value
+	case SSO_ROLE_NAME:
+		c.SSORoleName = value
+	case SSO_SESSION:
+		c.SSOSession = value
+	case SERVICES:
+		c.Services = value
+	case ENDPOINT_URL:
+		c.EndpointURL = value
+	case IGNORE_CONFIGURED_ENDPOINT_URLS:
+		c.IgnoreConfiguredEndpointURLs = parseBool(value)
+	case RETRY_MODE:
+		c.RetryMode = value
+	case MAX_ATTEMPTS:
+		c.MaxAttempts, err = strconv.Atoi(value)
+	case DEFAULTS_MODE:
+		c.DefaultsMode = value
+	case USE_FIPS_ENDPOINT:
+		c.UseFIPSEndpoint = parseBool(value)
+	case USE_DUALSTACK_ENDPOINT:
+		c.UseDualstackEndpoint = parseBool(value)
+	case STS_REGIONAL_ENDPOINTS:
+		c.STSRegionalEndpoints = value
+	case EC2_METADATA_SERVICE_ENDPOINT:
+		c.EC2MetadataServiceEndpoint = value
+	case EC2_METADATA_SERVICE_ENDPOINT_MODE:
+		c.EC2MetadataServiceEndpointMode = value
+	case METADATA_SERVICE_TIMEOUT:
+		c.MetadataServiceTimeout, err = strconv.Atoi(value)
+	case METADATA_SERVICE_NUM_ATTEMPTS:
+		c.MetadataServiceNumAttempts, err = strconv.Atoi(value)
+	case CLI_PAGER:
+		c.CliPager = value
+	case CLI_AUTO_PROMPT:
+		c.CliAutoPrompt = value
+	case PARAMETER_VALIDATION:
+		c.ParameterValidation = parseBool(value)
+	case TCP_KEEPALIVE:
+		c.TCPKeepalive = parseBool(value)
+	case SOURCE_IDENTITY:
+		c.SourceIdentity = value
+	default:
+		setExtra(&c.Extra, key, value)
+		return nil
+	}
+
+	if err == nil {
+		c.setPresent(key)
+		c.setWritten(key, value)
+	}
+
+	return err
+}
+
+// parseBool parse a boolean like ensure_boolean of botocore, which takes anything other than true as false
+func parseBool(value string) bool {
+	return strings.EqualFold(value, "true")
+}
+
+// formatInt returns the value of key as written in the file, e.g. 03 or +900, unless value is changed
+func formatInt(written map[string]string, key string, value int) string {
+	if n, err := strconv.Atoi(written[key]); err == nil && n == value {
+		return written[key]
+	}
+
+	return strconv.Itoa(value)
+}
+
+// formatBool returns the value of key as written in the file, e.g. TRUE, unless value is changed
+func formatBool(written map[string]string, key string, value bool) string {
+	if text, ok := written[key]; ok && parseBool(text) == value {
+		return text
+	}
+
+	return strconv.FormatBool(value)
+}
+
+func (c *Config) keyValues() []keyValue {
+	var kvs []keyValue
+
+	kvs = appendKeyValue(kvs, AwsAccessKeyID, c.AwsAccessKeyID)
+	kvs = appendKeyValue(kvs, AwsSecretAccessKey, c.AwsSecretAccessKey)
+	kvs = appendKeyValue(kvs, ROLE_ARN, c.RoleArn)
+	kvs = appendKeyValue(kvs, SOURCE_PROFILE, c.SourceProfile)
+	kvs = appendKeyValue(kvs, CREDENTIAL_SOURCE, c.CredentialSource)
+	kvs = appendKeyValue(kvs, ROLE_SESSION_NAME, c.RoleSessionName)
+	kvs = appendKeyValue(kvs, MFA_SERIAL, c.MfaSerial)
+	if c.DurationSeconds != ZeroInt || c.present[DURATION_SECONDS] {
+		kvs = appendKeyValue(kvs, DURATION_SECONDS, formatInt(c.written, DURATION_SECONDS, c.DurationSeconds))
+	}
+	kvs = appendKeyValue(kvs, AWS_SESSION_TOKEN, c.AwsSessionToken)
+	kvs = appendKeyValue(kvs, EXTERNAL_ID, c.ExternalID)
+	kvs = appendKeyValue(kvs, CA_BUNDLE, c.CaBundle)
+	kvs = appendKeyValue(kvs, CLI_FOLLOW_URLPARAM, c.CliFollowUrlparam)
+	kvs = appendKeyValue(kvs, CLI_TIMESTAMP_FORMAT, c.CliTimestampFormat)
+	kvs = appendKeyValue(kvs, CREDENTIAL_PROCESS, c.CredentialProcess)
+	kvs = appendKeyValue(kvs, WEB_IDENTITY_TOKEN_FILE, c.WebIdentityTokenFile)
+	kvs = appendKeyValue(kvs, OUTPUT, c.Output)
+	kvs = appendKeyValue(kvs, REGION, c.Region)
+	kvs = appendKeyValue(kvs, SSO_START_URL, c.SSOStartURL)
+	kvs = appendKeyValue(kvs, SSO_REGION, c.SSORegion)
+	kvs = appendKeyValue(kvs, SSO_ACCOUNT_ID, c.SSOAccountID)
+	kvs = appendKeyValue(kvs, SSO_ROLE_NAME, c.SSORoleName)
+	kvs = appendKeyValue(kvs, SSO_SESSION, c.SSOSession)
+	kvs = appendKeyValue(kvs, SERVICES, c.Services)
+	kvs = appendKeyValue(kvs, ENDPOINT_URL, c.EndpointURL)
+	if c.IgnoreConfiguredEndpointURLs || c.present[IGNORE_CONFIGURED_ENDPOINT_URLS] {
+		kvs = appendKeyValue(kvs, IGNORE_CONFIGURED_ENDPOINT_URLS, formatBool(c.written, IGNORE_CONFIGURED_ENDPOINT_URLS, c.IgnoreConfiguredEndpointURLs))
+	}
+	kvs = appendKeyValue(kvs, RETRY_MODE, c.RetryMode)
+	if c.MaxAttempts != ZeroInt || c.present[MAX_ATTEMPTS] {
+		kvs = appendKeyValue(kvs, MAX_ATTEMPTS, formatInt(c.written, MAX_ATTEMPTS, c.MaxAttempts))
+	}
+	kvs = appendKeyValue(kvs, DEFAULTS_MODE, c.DefaultsMode)
+	if c.UseFIPSEndpoint || c.present[USE_FIPS_ENDPOINT] {
+		kvs = appendKeyValue(kvs, USE_FIPS_ENDPOINT, formatBool(c.written, USE_FIPS_ENDPOINT, c.UseFIPSEndpoint))
+	}
+	if c.UseDualstackEndpoint || c.present[USE_DUALSTACK_ENDPOINT] {
+		kvs = appendKeyValue(kvs, USE_DUALSTACK_ENDPOINT, formatBool(c.written, USE_DUALSTACK_ENDPOINT, c.UseDualstackEndpoint))
+	}
+	kvs = appendKeyValue(kvs, STS_REGIONAL_ENDPOINTS, c.STSRegionalEndpoints)
+	kvs = appendKeyValue(kvs, EC2_METADATA_SERVICE_ENDPOINT, c.EC2MetadataServiceEndpoint)
+	kvs = appendKeyValue(kvs, EC2_METADATA_SERVICE_ENDPOINT_MODE, c.EC2MetadataServiceEndpointMode)
+	if c.MetadataServiceTimeout != ZeroInt || c.present[METADATA_SERVICE_TIMEOUT] {
+		kvs = appendKeyValue(kvs, METADATA_SERVICE_TIMEOUT, formatInt(c.written, METADATA_SERVICE_TIMEOUT, c.MetadataServiceTimeout))
+	}
+	if c.MetadataServiceNumAttempts != ZeroInt || c.present[METADATA_SERVICE_NUM_ATTEMPTS] {
+		kvs = appendKeyValue(kvs, METADATA_SERVICE_NUM_ATTEMPTS, formatInt(c.written, METADATA_SERVICE_NUM_ATTEMPTS, c.MetadataServiceNumAttempts))
+	}
+	kvs = appendKeyValue(kvs, CLI_PAGER, c.CliPager)
+	kvs = appendKeyValue(kvs, CLI_AUTO_PROMPT, c.CliAutoPrompt)
+	if c.ParameterValidation || c.present[PARAMETER_VALIDATION] {
+		kvs = appendKeyValue(kvs, PARAMETER_VALIDATION, formatBool(c.written, PARAMETER_VALIDATION, c.ParameterValidation))
+	}
+	if c.TCPKeepalive || c.present[TCP_KEEPALIVE] {
+		kvs = appendKeyValue(kvs, TCP_KEEPALIVE, formatBool(c.written, TCP_KEEPALIVE, c.TCPKeepalive))
+	}
+	kvs = appendKeyValue(kvs, SOURCE_IDENTITY, c.SourceIdentity)
+	kvs = appendExtra(kvs, c.Extra)
+	kvs = appendNested(kvs, S3, c.S3.keyValues(S3, c.present, c.written), c.Nested[S3])
+	kvs = appendNested(kvs, S3API, c.S3API.keyValues(S3API, c.present, c.written), c.Nested[S3API])
+
+	var keys []string
+	for key := range c.Nested {
+		if key != S3 && key != S3API {
+			keys = append(keys, key)
 		}
 	}
+	sort.Strings(keys)
 
-	return nil, false
+	for _, key := range keys {
+		kvs = appendNested(kvs, key, nil, c.Nested[key])
+	}
+
+	return kvs
+}
+
+// setNested set a nested value of the config file.
+// Nested keys without a field are kept in Nested.
+func (c *Config) setNested(key string, kv keyValue) error {
+	var ok bool
+	var err error
+
+	switch key {
+	case S3:
+		ok, err = c.S3.setValue(kv.key, kv.value)
+	case S3API:
+		ok, err = c.S3API.setValue(kv.key, kv.value)
+	}
+
+	if err != nil {
+		return err
+	}
+
+	if ok {
+		c.setPresent(key + "." + kv.key)
+		c.setWritten(key+"."+kv.key, kv.value)
+	} else {
+		if c.Nested == nil {
+			c.Nested = make(map[string]map[string]string)
+		}
+
+		if c.Nested[key] == nil {
+			c.Nested[key] = make(map[string]string)
+		}
+
+		c.Nested[key][kv.key] = kv.value
+	}
+
+	return nil
+}
+
+// appendNested append a key with nested values of fields and of a map
+func appendNested(kvs []keyValue, key string, nested []keyValue, m map[string]string) []keyValue {
+	nested = append(nested, sortedKeyValues(m)...)
+	if len(nested) == 0 {
+		return kvs
+	}
+
+	return append(kvs, keyValue{key: key, nested: nested})
+}
+
+func (c *Config) GetAwsAccessKeyID() string {
+	return c.AwsAccessKeyID
+}
+
+func (c *Config) GetAwsSecretAccessKey() string {
+	return c.AwsSecretAccessKey
 }
 
 func (c *Config) GetRoleArn() string {
@@ -331,10 +1109,21 @@ func (c *Config) GetAwsSessionToken() string {
 	return c.AwsSessionToken
 }
 
-func (c *Config) GetExternalID() int {
+func (c *Config) GetExternalID() string {
 	return c.ExternalID
 }
 
+// GetExternalIDInt get external_id as int. It returns an error when external_id is not a number.
+//
+// Deprecated: external_id is not always a number. Use GetExternalID instead.
+func (c *Config) GetExternalIDInt() (int, error) {
+	if c.ExternalID == EmptyString {
+		return ZeroInt, nil
+	}
+
+	return strconv.Atoi(c.ExternalID)
+}
+
 func (c *Config) GetCaBundle() string {
 	return c.CaBundle
 }
@@ -363,18 +1152,110 @@ func (c *Config) GetRegion() string {
 	return c.Region
 }
 
-func GetConfigsPath() (string, error) {
-	configsFile, err := homedir.Expand(AWS_CONFIG)
-	if err != nil {
-		return EmptyString, err
-	}
+func (c *Config) GetSSOStartURL() string {
+	return c.SSOStartURL
+}
 
-	if os.Getenv(AWS_CONFIG_FILE) != EmptyString {
-		configsFile, err = homedir.Expand(os.Getenv(AWS_CONFIG_FILE))
-		if err != nil {
-			return EmptyString, err
-		}
-	}
+func (c *Config) GetSSORegion() string {
+	return c.SSORegion
+}
+
+func (c *Config) GetSSOAccountID() string {
+	return c.SSOAccountID
+}
 
-	return configsFile, nil
+func (c *Config) GetSSORoleName() string {
+	return c.SSORoleName
+}
+
+func (c *Config) GetSSOSession() string {
+	return c.SSOSession
+}
+
+func (c *Config) GetServices() string {
+	return c.Services
+}
+
+func (c *Config) GetEndpointURL() string {
+	return c.EndpointURL
+}
+
+func (c *Config) GetIgnoreConfiguredEndpointURLs() bool {
+	return c.IgnoreConfiguredEndpointURLs
+}
+
+func (c *Config) GetS3() S3Settings {
+	return c.S3
+}
+
+func (c *Config) GetS3API() S3Settings {
+	return c.S3API
+}
+
+func (c *Config) GetNested(key string) map[string]string {
+	return c.Nested[key]
+}
+
+func (c *Config) GetRetryMode() string {
+	return c.RetryMode
+}
+
+func (c *Config) GetMaxAttempts() int {
+	return c.MaxAttempts
+}
+
+func (c *Config) GetDefaultsMode() string {
+	return c.DefaultsMode
+}
+
+func (c *Config) GetUseFIPSEndpoint() bool {
+	return c.UseFIPSEndpoint
+}
+
+func (c *Config) GetUseDualstackEndpoint() bool {
+	return c.UseDualstackEndpoint
+}
+
+func (c *Config) GetSTSRegionalEndpoints() string {
+	return c.STSRegionalEndpoints
+}
+
+func (c *Config) GetEC2MetadataServiceEndpoint() string {
+	return c.EC2MetadataServiceEndpoint
+}
+
+func (c *Config) GetEC2MetadataServiceEndpointMode() string {
+	return c.EC2MetadataServiceEndpointMode
+}
+
+func (c *Config) GetMetadataServiceTimeout() int {
+	return c.MetadataServiceTimeout
+}
+
+func (c *Config) GetMetadataServiceNumAttempts() int {
+	return c.MetadataServiceNumAttempts
+}
+
+func (c *Config) GetCliPager() string {
+	return c.CliPager
+}
+
+func (c *Config) GetCliAutoPrompt() string {
+	return c.CliAutoPrompt
+}
+
+func (c *Config) GetParameterValidation() bool {
+	return c.ParameterValidation
+}
+
+func (c *Config) GetTCPKeepalive() bool {
+	return c.TCPKeepalive
+}
+
+func (c *Config) GetSourceIdentity() string {
+	return c.SourceIdentity
+}
+
+func GetConfigsPath() (string, error) {
+	return options{}.configPath()
 }
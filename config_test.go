@@ -3,8 +3,12 @@ package awsprofile_test
 import (
 	"errors"
 	"fmt"
+	"io/ioutil"
 	"log"
 	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
 	"testing"
 
 	"github.com/youyo/awsprofile"
@@ -172,9 +176,15 @@ func TestConfigs_GetExternalID(t *testing.T) {
 
 	if value, err := config.GetExternalID("bar"); err != nil {
 		t.Fatal(err)
-	} else if value != 12345 {
+	} else if value != "12345" {
 		t.Fatal(errors.New("Unmatched ExternalID"))
 	}
+
+	if value, err := config.GetExternalIDInt("bar"); err != nil {
+		t.Fatal(err)
+	} else if value != 12345 {
+		t.Fatal(errors.New("Unmatched ExternalIDInt"))
+	}
 }
 
 func TestConfigs_GetCaBundle(t *testing.T) {
@@ -366,7 +376,7 @@ func TestConfig_GetExternalID(t *testing.T) {
 	awsProfile.Parse()
 
 	if ok, config := awsProfile.IsConfig("bar"); ok {
-		if config.GetExternalID() != 12345 {
+		if config.GetExternalID() != "12345" {
 			t.Fatal(errors.New("Unmatched ExternalID"))
 		}
 	}
@@ -449,3 +459,175 @@ func TestConfig_GetRegion(t *testing.T) {
 		}
 	}
 }
+
+func TestConfigs_Parse_Modern(t *testing.T) {
+	configs := awsprofile.NewConfigs()
+	if err := configs.Parse("./tests/modern/.aws/config"); err != nil {
+		t.Fatal(err)
+	}
+
+	if value, err := configs.GetRetryMode("modern"); err != nil {
+		t.Fatal(err)
+	} else if value != "adaptive" {
+		t.Fatal("Unmatched RetryMode", value)
+	}
+
+	if value, err := configs.GetMaxAttempts("modern"); err != nil {
+		t.Fatal(err)
+	} else if value != 5 {
+		t.Fatal("Unmatched MaxAttempts", value)
+	}
+
+	if _, err := configs.GetRetryMode("fooooo"); !errors.Is(err, awsprofile.ErrorNotFoundRetryMode) {
+		t.Fatal("Unexpected error", err)
+	}
+
+	config := findConfig(configs, "modern")
+
+	tests := []struct {
+		name   string
+		value  interface{}
+		expect interface{}
+	}{
+		{"DefaultsMode", config.GetDefaultsMode(), "in-region"},
+		{"UseFIPSEndpoint", config.GetUseFIPSEndpoint(), true},
+		{"UseDualstackEndpoint", config.GetUseDualstackEndpoint(), true},
+		{"STSRegionalEndpoints", config.GetSTSRegionalEndpoints(), "regional"},
+		{"EC2MetadataServiceEndpoint", config.GetEC2MetadataServiceEndpoint(), "http://[fd00:ec2::254]"},
+		{"EC2MetadataServiceEndpointMode", config.GetEC2MetadataServiceEndpointMode(), "ipv6"},
+		{"MetadataServiceTimeout", config.GetMetadataServiceTimeout(), 2},
+		{"MetadataServiceNumAttempts", config.GetMetadataServiceNumAttempts(), 3},
+		{"CliPager", config.GetCliPager(), "less -R"},
+		{"CliAutoPrompt", config.GetCliAutoPrompt(), "on-partial"},
+		{"ParameterValidation", config.GetParameterValidation(), false},
+		{"TCPKeepalive", config.GetTCPKeepalive(), true},
+		{"RoleSessionName", config.GetRoleSessionName(), "modern-session"},
+		{"SourceIdentity", config.GetSourceIdentity(), "alice"},
+		{"ExternalID", config.GetExternalID(), "0042e0b8-7f1c-4a55-9d3e-5f3b8c1e2a90"},
+	}
+
+	for _, tt := range tests {
+		if tt.value != tt.expect {
+			t.Error("Unmatched", tt.name, tt.value, "expect", tt.expect)
+		}
+	}
+
+	if _, err := config.GetExternalIDInt(); err == nil {
+		t.Error("GetExternalIDInt should fail for a UUID")
+	}
+}
+
+func TestConfigs_Parse_InvalidValue(t *testing.T) {
+	dir := tempDir(t)
+	defer os.RemoveAll(dir)
+
+	tests := []string{
+		"max_attempts = three",
+		"duration_seconds = 1h",
+		"metadata_service_timeout = 1.5",
+	}
+
+	for _, tt := range tests {
+		file := filepath.Join(dir, "config")
+		if err := ioutil.WriteFile(file, []byte("[profile invalid]\n"+tt+"\n"), awsprofile.FileMode); err != nil {
+			t.Fatal(err)
+		}
+
+		if err := awsprofile.NewConfigs().Parse(file); err == nil {
+			t.Error("Parse should fail", tt)
+		}
+	}
+}
+
+func TestConfigs_Parse_LooseValue(t *testing.T) {
+	dir := tempDir(t)
+	defer os.RemoveAll(dir)
+
+	// like botocore, booleans other than true are false, and enums are checked only when they are used
+	data := `[profile loose]
+tcp_keepalive = yes
+use_fips_endpoint = 1
+parameter_validation = True
+retry_mode = fast
+`
+
+	file := filepath.Join(dir, "config")
+	if err := ioutil.WriteFile(file, []byte(data), awsprofile.FileMode); err != nil {
+		t.Fatal(err)
+	}
+
+	configs := awsprofile.NewConfigs()
+	if err := configs.Parse(file); err != nil {
+		t.Fatal(err)
+	}
+
+	config := findConfig(configs, "loose")
+	if config.TCPKeepalive || config.UseFIPSEndpoint || !config.ParameterValidation || config.RetryMode != "fast" {
+		t.Error("Unmatched values", config.TCPKeepalive, config.UseFIPSEndpoint, config.ParameterValidation, config.RetryMode)
+	}
+
+	if err := configs.Save(file); err != nil {
+		t.Fatal(err)
+	}
+
+	assertFile(t, file, data)
+}
+
+func BenchmarkConfigs_GetRegion(b *testing.B) {
+	for _, n := range []int{10, 100, 1000, 10000} {
+		configs := awsprofile.NewConfigs()
+		for i := 0; i < n; i++ {
+			configs.Set(awsprofile.Config{ProfileName: "profile-" + strconv.Itoa(i), Region: "us-east-1"})
+		}
+
+		last := "profile-" + strconv.Itoa(n-1)
+
+		b.Run(strconv.Itoa(n), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				if _, err := configs.GetRegion(last); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+func ExampleConfigs_ParseReader() {
+	configs := awsprofile.NewConfigs()
+
+	err := configs.ParseReader(strings.NewReader("[profile ci]\nregion = us-east-1\nmax_attempts = many\n"), "stdin")
+	fmt.Println(err)
+	// Output: stdin:3: profile ci: max_attempts: strconv.Atoi: parsing "many": invalid syntax
+}
+
+func TestConfigs_ParseBytes(t *testing.T) {
+	data, err := ioutil.ReadFile("./tests/.aws/config")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	configs := awsprofile.NewConfigs()
+	if err := configs.ParseBytes(data, "secret"); err != nil {
+		t.Fatal(err)
+	}
+
+	expect := awsprofile.NewConfigs()
+	if err := expect.Parse("./tests/.aws/config"); err != nil {
+		t.Fatal(err)
+	}
+
+	assertExported(t, configs.List(), expect.List())
+
+	bar := findConfig(configs, "bar")
+	if origin, _ := bar.Origin("region"); origin.Path != "secret" || origin.Line != 20 {
+		t.Error("Unmatched Origin", origin)
+	}
+
+	if err := configs.ParseBytes([]byte("region = us-east-1\n[profile"), ""); err == nil || strings.HasPrefix(err.Error(), ":") {
+		t.Error("Unexpected error", err)
+	}
+
+	if profiles, _ := configs.ProfileNames(); len(profiles) != 3 {
+		t.Error("configs are changed by a failed ParseBytes", profiles)
+	}
+}
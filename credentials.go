@@ -1,11 +1,10 @@
 package awsprofile
 
 import (
+	"bytes"
 	"errors"
-	"os"
-
-	homedir "github.com/mitchellh/go-homedir"
-	ini "gopkg.in/ini.v1"
+	"io"
+	"time"
 )
 
 // constant
@@ -14,64 +13,287 @@ const (
 	AwsCredentials           string = "~/.aws/credentials"
 	AwsAccessKeyID           string = "aws_access_key_id"
 	AwsSecretAccessKey       string = "aws_secret_access_key"
+	AwsExpiration            string = "aws_expiration"
+	XSecurityTokenExpires    string = "x_security_token_expires"
 )
 
 // error messages
 var (
 	ErrorNotFoundAwsAccessKeyID     = errors.New(AwsAccessKeyID + ErrorNotFound)
 	ErrorNotFoundAwsSecretAccessKey = errors.New(AwsSecretAccessKey + ErrorNotFound)
+	ErrorNotFoundExpiration         = errors.New(AwsExpiration + ErrorNotFound)
 )
 
-// Credential provide credentials
+// Credential provide credentials.
+// Expiration is aws_expiration or x_security_token_expires in RFC 3339, which tools such as saml2aws write for temporary credentials.
+// Config has the other keys of config file, such as region and role_arn, which the AWS CLI also reads from credentials file.
+// Extra has only keys which neither Credential nor Config has.
 type Credential struct {
 	ProfileName        string
 	AwsAccessKeyID     string
 	AwsSecretAccessKey string
+	AwsSessionToken    string
+	Expiration         time.Time
+	Config             Config
+	Extra              map[string]string
+	origins            origins
+
+	// expirationKey and expirationValue are the key and the value of Expiration as written
+	expirationKey   string
+	expirationValue string
 }
 
-// Credentials has many Credential
-type Credentials []Credential
+// Credentials has many Credential in the order of credentials file.
+// Credentials are indexed by profile name, so a lookup does not depend on the number of profiles.
+type Credentials struct {
+	list  []*Credential
+	index map[string]int
+
+	// skipped are profiles which ParseLenient skipped, whose sections Save keeps as they are
+	skipped map[string]bool
+}
 
 // NewCredentials create a new Credentials instance
 func NewCredentials() *Credentials {
 	return new(Credentials)
 }
 
-// Parse credential file
+// Parse credential file, and replace credentials with the profiles of credential file.
+// It stops at the first invalid line or value, and returns it as *ParseError without changing credentials.
 func (c *Credentials) Parse(credentialsFile string) error {
-	data, err := ini.Load(credentialsFile)
+	return parseFile(credentialsFile, false, c.parse)
+}
+
+// ParseLenient parse credential file like Parse, but it does not stop at invalid lines and values.
+// Profiles which have no errors are all parsed, and errors are returned as ParseErrors.
+func (c *Credentials) ParseLenient(credentialsFile string) error {
+	return parseFile(credentialsFile, true, c.parse)
+}
+
+// ParseBytes parse data like Parse.
+// source is used as the path of *ParseError, e.g. the name of a secret, and can be empty.
+func (c *Credentials) ParseBytes(data []byte, source string) error {
+	return c.parse(data, source, false)
+}
+
+// ParseReader read r to the end and parse it like ParseBytes
+func (c *Credentials) ParseReader(r io.Reader, source string) error {
+	return parseReader(r, source, false, c.parse)
+}
+
+func (c *Credentials) parse(data []byte, source string, lenient bool) error {
+	d, errs, err := parseSource(data, source, lenient)
 	if err != nil {
 		return err
 	}
 
-	for _, section := range data.Sections() {
-		if section.Name() == "DEFAULT" {
+	parsed := Credentials{}
+
+	for _, section := range d.sections {
+		if section.header == nil || section.name == "DEFAULT" {
+			continue
+		}
+
+		if section.invalid() {
+			parsed.skipped = skipProfile(parsed.skipped, section.name)
 			continue
 		}
 
 		credential := Credential{}
 
-		credential.ProfileName = section.Name()
+		credential.ProfileName = section.name
+
+		var sectionErrs ParseErrors
+
+		for _, kv := range section.keyValues() {
+			credential.origins.set(kv.key, Origin{Path: source, Line: kv.number, Section: section.name})
+
+			if kv.nested == nil {
+				if err := credential.setValue(kv.key, kv.value); err != nil {
+					sectionErrs = append(sectionErrs, &ParseError{Path: source, Line: kv.number, Profile: section.name, Key: kv.key, Err: err})
+				}
+				continue
+			}
+
+			for _, nested := range kv.nested {
+				credential.origins.set(kv.key+"."+nested.key, Origin{Path: source, Line: nested.number, Section: section.name})
+
+				if err := credential.Config.setNested(kv.key, nested); err != nil {
+					sectionErrs = append(sectionErrs, &ParseError{Path: source, Line: nested.number, Profile: section.name, Key: kv.key + "." + nested.key, Err: err})
+				}
+			}
+		}
+
+		if sectionErrs != nil && !lenient {
+			return sectionErrs[0]
+		}
+
+		// a profile which has errors is not parsed cleanly
+		if sectionErrs != nil {
+			errs = append(errs, sectionErrs...)
+			parsed.skipped = skipProfile(parsed.skipped, section.name)
+			continue
+		}
+
+		parsed.Set(credential)
+	}
+
+	*c = parsed
+
+	errs.sortByLine()
+
+	return errs.err()
+}
+
+// Set add a credential, or replace the credential of the same profile.
+// A replaced credential is updated in place, so *Credential returned by IsCredential keeps pointing to the profile.
+func (c *Credentials) Set(credential Credential) {
+	if i, ok := c.index[credential.ProfileName]; ok {
+		*c.list[i] = credential
+		return
+	}
+
+	if c.index == nil {
+		c.index = make(map[string]int)
+	}
+
+	c.index[credential.ProfileName] = len(c.list)
+	c.list = append(c.list, &credential)
+}
+
+// Delete remove a credential. A profile which ParseLenient skipped is removed by Save too.
+func (c *Credentials) Delete(profileName string) bool {
+	i, ok := c.index[profileName]
+	if !ok {
+		if c.skipped[profileName] {
+			delete(c.skipped, profileName)
+			return true
+		}
+
+		return false
+	}
+
+	c.list = append(c.list[:i], c.list[i+1:]...)
+
+	delete(c.index, profileName)
+	for j := i; j < len(c.list); j++ {
+		c.index[c.list[j].ProfileName] = j
+	}
+
+	return true
+}
+
+// List get credentials in the order of credentials file
+func (c *Credentials) List() []Credential {
+	var list []Credential
+	for _, credential := range c.list {
+		list = append(list, *credential)
+	}
+
+	return list
+}
+
+// Len get the number of credentials
+func (c *Credentials) Len() int {
+	return len(c.list)
+}
+
+// WriteTo write credentials in credentials file format
+func (c *Credentials) WriteTo(w io.Writer) (int64, error) {
+	d := newDocument()
+	if err := c.patch(d); err != nil {
+		return 0, err
+	}
+
+	return bytes.NewBuffer(d.Bytes()).WriteTo(w)
+}
+
+// Save write credentials file atomically.
+// If credentialsFile exists, only the lines of changed keys are rewritten,
+// and comments, blank lines, order and unknown keys are kept as they are.
+// Sections of profiles which ParseLenient skipped are kept as they are.
+// Names, keys and values which cannot be parsed back as they are, such as a profile name with ], are errors.
+func (c *Credentials) Save(credentialsFile string) error {
+	d, err := loadDocument(credentialsFile, c.skipped != nil)
+	if err != nil {
+		return err
+	}
+
+	if err := c.patch(d); err != nil {
+		return err
+	}
+
+	return writeFileAtomic(credentialsFile, d.Bytes())
+}
+
+// patch make sections of d the same as c.
+// Sections of profiles which ParseLenient skipped are kept as they are.
+func (c *Credentials) patch(d *document) error {
+	sections := make(map[string]*section)
+
+	for _, section := range append([]*section(nil), d.sections...) {
+		if section.header == nil || section.name == "DEFAULT" {
+			continue
+		}
+
+		if !c.has(section.name) {
+			if !c.skipped[section.name] {
+				d.removeSection(section)
+			}
+			continue
+		}
+
+		sections[section.name] = section
+	}
 
-		if section.HasKey(AwsAccessKeyID) {
-			credential.AwsAccessKeyID = section.Key(AwsAccessKeyID).String()
+	for _, credential := range c.list {
+		kvs := credential.keyValues()
+		if err := checkSection(credential.ProfileName, kvs); err != nil {
+			return err
 		}
 
-		if section.HasKey(AwsSecretAccessKey) {
-			credential.AwsSecretAccessKey = section.Key(AwsSecretAccessKey).String()
+		section, ok := sections[credential.ProfileName]
+		if !ok {
+			section = d.addSection(credential.ProfileName)
 		}
 
-		*c = append(*c, credential)
+		// every key of the section is kept in Credential, in a field, Config or Extra
+		known := append(append([]string(nil), credentialKeys...), configKeys...)
+		for _, kv := range section.keyValues() {
+			known = append(known, kv.key)
+		}
+
+		section.apply(kvs, known)
 	}
 
 	return nil
 }
 
+// notFound returns NotFoundError of a profile which is not in c
+func (c *Credentials) notFound(profileName string, err error) error {
+	profileNames, _ := c.ProfileNames()
+	return profileNotFound(profileName, profileNames, err)
+}
+
+func (c *Credentials) has(profileName string) bool {
+	_, ok := c.index[profileName]
+	return ok
+}
+
+func (c *Credentials) get(profileName string) (*Credential, bool) {
+	i, ok := c.index[profileName]
+	if !ok {
+		return nil, false
+	}
+
+	return c.list[i], true
+}
+
 // ProfileNames get name of profiles
 func (c *Credentials) ProfileNames() ([]string, error) {
 	var profileNames []string
 
-	for _, credential := range *c {
+	for _, credential := range c.list {
 		profileNames = append(profileNames, credential.ProfileName)
 	}
 
@@ -80,24 +302,124 @@ func (c *Credentials) ProfileNames() ([]string, error) {
 
 // GetAwsAccessKeyID get aws_access_key_id
 func (c *Credentials) GetAwsAccessKeyID(profileName string) (string, error) {
-	for _, credential := range *c {
-		if credential.ProfileName == profileName {
-			return credential.AwsAccessKeyID, nil
-		}
+	if credential, ok := c.get(profileName); ok {
+		return credential.AwsAccessKeyID, nil
 	}
 
-	return EmptyString, ErrorNotFoundAwsAccessKeyID
+	return EmptyString, c.notFound(profileName, ErrorNotFoundAwsAccessKeyID)
 }
 
 // GetAwsSecretAccessKey get aws_secret_access_key
 func (c *Credentials) GetAwsSecretAccessKey(profileName string) (string, error) {
-	for _, credential := range *c {
-		if credential.ProfileName == profileName {
-			return credential.AwsSecretAccessKey, nil
+	if credential, ok := c.get(profileName); ok {
+		return credential.AwsSecretAccessKey, nil
+	}
+
+	return EmptyString, c.notFound(profileName, ErrorNotFoundAwsSecretAccessKey)
+}
+
+// GetAwsSessionToken get aws_session_token
+func (c *Credentials) GetAwsSessionToken(profileName string) (string, error) {
+	if credential, ok := c.get(profileName); ok {
+		return credential.AwsSessionToken, nil
+	}
+
+	return EmptyString, c.notFound(profileName, ErrorNotFoundAwsSessionToken)
+}
+
+// GetExpiration get aws_expiration or x_security_token_expires
+func (c *Credentials) GetExpiration(profileName string) (time.Time, error) {
+	if credential, ok := c.get(profileName); ok {
+		return credential.Expiration, nil
+	}
+
+	return time.Time{}, c.notFound(profileName, ErrorNotFoundExpiration)
+}
+
+// credentialKeys are keys of Credential in the order of writing
+var credentialKeys = []string{
+	AwsAccessKeyID,
+	AwsSecretAccessKey,
+	AWS_SESSION_TOKEN,
+	AwsExpiration,
+	XSecurityTokenExpires,
+}
+
+// setValue set a value of the credentials file. Keys of config file are set to Config,
+// and unknown keys and keys with an empty value are kept in Extra.
+// When both aws_expiration and x_security_token_expires are set, the first one is Expiration and the other is kept in Extra.
+func (c *Credential) setValue(key, value string) error {
+	if value == EmptyString {
+		setExtra(&c.Extra, key, value)
+		return nil
+	}
+
+	switch key {
+	case AwsAccessKeyID:
+		c.AwsAccessKeyID = value
+	case AwsSecretAccessKey:
+		c.AwsSecretAccessKey = value
+	case AWS_SESSION_TOKEN:
+		c.AwsSessionToken = value
+	case AwsExpiration, XSecurityTokenExpires:
+		if c.expirationKey != EmptyString && c.expirationKey != key {
+			setExtra(&c.Extra, key, value)
+			return nil
 		}
+
+		expiration, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			return err
+		}
+
+		c.Expiration, c.expirationKey, c.expirationValue = expiration, key, value
+	default:
+		if !isConfigKey(key) {
+			setExtra(&c.Extra, key, value)
+			return nil
+		}
+
+		return c.Config.setValue(key, value)
+	}
+
+	return nil
+}
+
+func (c *Credential) keyValues() []keyValue {
+	var kvs []keyValue
+
+	kvs = appendKeyValue(kvs, AwsAccessKeyID, c.AwsAccessKeyID)
+	kvs = appendKeyValue(kvs, AwsSecretAccessKey, c.AwsSecretAccessKey)
+	kvs = appendKeyValue(kvs, AWS_SESSION_TOKEN, c.AwsSessionToken)
+	if !c.Expiration.IsZero() {
+		kvs = appendKeyValue(kvs, c.expirationKeyName(), c.expirationString())
+	}
+	for _, kv := range c.Config.keyValues() {
+		if !hasKeyValue(kvs, kv.key) {
+			kvs = append(kvs, kv)
+		}
+	}
+	kvs = appendExtra(kvs, c.Extra)
+
+	return kvs
+}
+
+// expirationKeyName is the key of Expiration as written, or aws_expiration for a new one
+func (c *Credential) expirationKeyName() string {
+	if c.expirationKey != EmptyString {
+		return c.expirationKey
+	}
+
+	return AwsExpiration
+}
+
+// expirationString keeps the value as written unless Expiration is changed
+func (c *Credential) expirationString() string {
+	if written, err := time.Parse(time.RFC3339, c.expirationValue); err == nil && written.Equal(c.Expiration) {
+		return c.expirationValue
 	}
 
-	return EmptyString, ErrorNotFoundAwsSecretAccessKey
+	return c.Expiration.Format(time.RFC3339)
 }
 
 // GetAwsAccessKeyID get aws_access_key_id
@@ -110,19 +432,38 @@ func (c *Credential) GetAwsSecretAccessKey() string {
 	return c.AwsSecretAccessKey
 }
 
-// GetCredentialsPath provide file path to credentials
-func GetCredentialsPath() (string, error) {
-	credentialsFile, err := homedir.Expand(AwsCredentials)
-	if err != nil {
-		return EmptyString, err
-	}
+// GetAwsSessionToken get aws_session_token
+func (c *Credential) GetAwsSessionToken() string {
+	return c.AwsSessionToken
+}
 
-	if os.Getenv(AwsSharedCredentialsFile) != EmptyString {
-		credentialsFile, err = homedir.Expand(os.Getenv(AwsSharedCredentialsFile))
-		if err != nil {
-			return EmptyString, err
-		}
+// GetExpiration get aws_expiration or x_security_token_expires
+func (c *Credential) GetExpiration() time.Time {
+	return c.Expiration
+}
+
+// IsTemporary reports whether the credential is temporary, which has aws_session_token
+func (c *Credential) IsTemporary() bool {
+	return c.AwsSessionToken != EmptyString
+}
+
+// ExpiresIn get the duration until the credential expires, which is negative when it is expired.
+// ok is false when the expiration is not set.
+func (c *Credential) ExpiresIn() (time.Duration, bool) {
+	if c.Expiration.IsZero() {
+		return 0, false
 	}
 
-	return credentialsFile, nil
+	return time.Until(c.Expiration), true
+}
+
+// IsExpired reports whether the expiration is set and has passed
+func (c *Credential) IsExpired() bool {
+	expiresIn, ok := c.ExpiresIn()
+	return ok && expiresIn <= 0
+}
+
+// GetCredentialsPath provide file path to credentials
+func GetCredentialsPath() (string, error) {
+	return options{}.credentialsPath()
 }
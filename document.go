@@ -0,0 +1,469 @@
+package awsprofile
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+)
+
+// lineKind is kind of a line in a document
+type lineKind int
+
+// kinds of line
+const (
+	lineBlank lineKind = iota
+	lineComment
+	lineSection
+	lineKey
+	lineNested
+	lineContinuation
+	lineInvalid
+)
+
+// line is a line of a document. raw keeps the line as it is, including the line break.
+type line struct {
+	raw    string
+	kind   lineKind
+	key    string
+	value  string
+	offset int
+	number int
+}
+
+// section is a section header and the lines up to the next section.
+// The first section of a document has no header and holds the lines before any section.
+type section struct {
+	name   string
+	header *line
+	lines  []*line
+}
+
+// document is an ini file which can be written back without losing comments, blank lines and order.
+// Only the lines changed by set, unset, addSection and removeSection are rewritten.
+type document struct {
+	sections []*section
+}
+
+func newDocument() *document {
+	return &document{sections: []*section{{}}}
+}
+
+// parseDocument parse data the same way as the AWS CLI.
+// Keys are case-insensitive, and lines indented deeper than a key with empty value are nested keys.
+// Lines which cannot be parsed are kept as they are, and returned as errs.
+func parseDocument(data []byte) (*document, ParseErrors) {
+	var errs ParseErrors
+
+	d := newDocument()
+	current := d.sections[0]
+
+	var parent *line
+	var parentIndent int
+
+	for i, raw := range strings.SplitAfter(string(data), "\n") {
+		if raw == EmptyString {
+			continue
+		}
+
+		l := &line{raw: raw, number: i + 1}
+		text := strings.TrimRight(raw, "\r\n")
+		trimmed := strings.TrimSpace(text)
+		indent := len(text) - len(strings.TrimLeft(text, " \t"))
+		child := parent != nil && indent > parentIndent
+
+		switch {
+		// like configparser, blank lines and comments do not end nested keys and continuation lines
+		case trimmed == EmptyString:
+			l.kind = lineBlank
+		case trimmed[0] == '#' || trimmed[0] == ';':
+			l.kind = lineComment
+		case trimmed[0] == '[' && !child:
+			closing := strings.LastIndex(trimmed, "]")
+			if closing < 0 {
+				errs = append(errs, &ParseError{Line: l.number, Err: fmt.Errorf("unclosed section header: %s", trimmed)})
+				l.kind = lineInvalid
+				parent = nil
+				break
+			}
+
+			l.kind = lineSection
+			current = &section{name: strings.TrimSpace(trimmed[1:closing]), header: l}
+			d.sections = append(d.sections, current)
+			parent = nil
+
+			continue
+		case child && (parent.value != EmptyString || !strings.ContainsAny(text, "=:")):
+			l.kind = lineContinuation
+			l.value = trimmed
+			parent.value += "\n" + trimmed
+		case child:
+			if err := l.parseKeyValue(text); err != nil {
+				errs = append(errs, &ParseError{Line: l.number, Err: err})
+				l.kind = lineInvalid
+				break
+			}
+
+			l.kind = lineNested
+		default:
+			if err := l.parseKeyValue(text); err != nil {
+				errs = append(errs, &ParseError{Line: l.number, Err: err})
+				l.kind = lineInvalid
+				parent = nil
+				break
+			}
+
+			l.kind = lineKey
+			parent = l
+			parentIndent = indent
+		}
+
+		current.lines = append(current.lines, l)
+	}
+
+	return d, errs
+}
+
+func (l *line) parseKeyValue(text string) error {
+	delimiter := strings.IndexAny(text, "=:")
+	if delimiter < 0 {
+		return fmt.Errorf("key-value delimiter is not found: %s", strings.TrimSpace(text))
+	}
+
+	l.key = strings.ToLower(strings.TrimSpace(text[:delimiter]))
+	l.offset = delimiter + 1
+
+	for l.offset < len(text) && (text[l.offset] == ' ' || text[l.offset] == '\t') {
+		l.offset++
+	}
+
+	l.value = strings.TrimSpace(text[l.offset:])
+
+	return nil
+}
+
+// WriteTo write the document as it is
+func (d *document) WriteTo(buf *bytes.Buffer) {
+	for _, s := range d.sections {
+		if s.header != nil {
+			buf.WriteString(s.header.raw)
+		}
+
+		for _, l := range s.lines {
+			buf.WriteString(l.raw)
+		}
+	}
+}
+
+// Bytes returns the document as it is
+func (d *document) Bytes() []byte {
+	var buf bytes.Buffer
+	d.WriteTo(&buf)
+
+	return buf.Bytes()
+}
+
+// keyValues returns keys of the section in order with their nested keys
+func (s *section) keyValues() []keyValue {
+	var kvs []keyValue
+
+	for i, l := range s.lines {
+		if l.kind != lineKey {
+			continue
+		}
+
+		kv := keyValue{key: l.key, value: l.value, number: l.number}
+
+		for _, child := range s.lines[i+1 : i+1+s.children(i)] {
+			if child.kind == lineNested {
+				kv.nested = append(kv.nested, keyValue{key: child.key, value: child.value, number: child.number})
+			}
+		}
+
+		kvs = append(kvs, kv)
+	}
+
+	return kvs
+}
+
+// invalid reports whether s has a line which cannot be parsed
+func (s *section) invalid() bool {
+	for _, l := range s.lines {
+		if l.kind == lineInvalid {
+			return true
+		}
+	}
+
+	return false
+}
+
+// find returns the last line of key, which is the one the AWS CLI reads
+func (s *section) find(key string) int {
+	found := -1
+
+	for i, l := range s.lines {
+		if l.kind == lineKey && l.key == key {
+			found = i
+		}
+	}
+
+	return found
+}
+
+// children returns the number of nested and continuation lines following the line at i,
+// including blank lines and comments between them
+func (s *section) children(i int) int {
+	n := 0
+
+	for j := i + 1; j < len(s.lines); j++ {
+		switch s.lines[j].kind {
+		case lineNested, lineContinuation:
+			n = j - i
+		case lineBlank, lineComment:
+			continue
+		default:
+			return n
+		}
+	}
+
+	return n
+}
+
+// set replace the value of key in place, or insert key after the last key of the section
+func (s *section) set(key, value string) {
+	i := s.find(key)
+	if i < 0 {
+		s.insert(s.end(), &line{raw: key + " = " + formatValue(value, "\n") + "\n", kind: lineKey, key: key, value: value})
+		return
+	}
+
+	if s.lines[i].value == value {
+		return
+	}
+
+	if n := s.children(i); n > 0 {
+		s.lines = append(s.lines[:i+1], s.lines[i+1+n:]...)
+	}
+
+	s.lines[i].replace(value)
+}
+
+// setNested replace nested keys of key in place.
+// Missing nested keys are inserted with the indent of the existing ones.
+func (s *section) setNested(key string, kvs []keyValue) {
+	i := s.find(key)
+	if i < 0 {
+		i = s.end()
+		s.insert(i, &line{raw: key + " =\n", kind: lineKey, key: key})
+	} else if s.lines[i].value != EmptyString {
+		if n := s.children(i); n > 0 {
+			s.lines = append(s.lines[:i+1], s.lines[i+1+n:]...)
+		}
+
+		s.lines[i].replace(EmptyString)
+	}
+
+	values := make(map[string]string)
+	for _, kv := range kvs {
+		values[kv.key] = kv.value
+	}
+
+	indent := s.indent(i)
+
+	for j := i + 1; j < i+1+s.children(i); {
+		child := s.lines[j]
+
+		if child.kind == lineBlank || child.kind == lineComment {
+			j++
+			continue
+		}
+
+		value, ok := values[child.key]
+		if child.kind != lineNested || !ok {
+			s.lines = append(s.lines[:j], s.lines[j+1:]...)
+			continue
+		}
+
+		if child.value != value {
+			child.replace(value)
+		}
+
+		delete(values, child.key)
+		j++
+	}
+
+	at := i + 1 + s.children(i)
+	for _, kv := range kvs {
+		if _, ok := values[kv.key]; !ok {
+			continue
+		}
+
+		s.insert(at, &line{raw: indent + kv.key + " = " + kv.value + "\n", kind: lineNested, key: kv.key, value: kv.value})
+		delete(values, kv.key)
+		at++
+	}
+}
+
+// indent returns the indent of nested keys of the line at i,
+// or of any nested keys in the section when it has none yet.
+func (s *section) indent(i int) string {
+	lines := s.lines[i+1 : i+1+s.children(i)]
+	if len(lines) == 0 {
+		lines = s.lines
+	}
+
+	for _, l := range lines {
+		if l.kind == lineNested {
+			text := strings.TrimRight(l.raw, "\r\n")
+			return text[:len(text)-len(strings.TrimLeft(text, " \t"))]
+		}
+	}
+
+	return "  "
+}
+
+// replace rewrite the value of the line, keeping the text before the value as it is
+func (l *line) replace(value string) {
+	text := strings.TrimRight(l.raw, "\r\n")
+	prefix := text[:l.offset]
+
+	// keep "key = value" style when the old value was empty
+	if l.offset == len(text) && len(prefix) >= 2 && prefix[len(prefix)-2] == ' ' {
+		prefix += " "
+	}
+
+	lineBreak := l.raw[len(text):]
+	if lineBreak == EmptyString && strings.Contains(value, "\n") {
+		lineBreak = "\n"
+	}
+
+	l.raw = prefix + formatValue(value, lineBreak) + lineBreak
+	l.value = value
+}
+
+// formatValue indent the lines after the first one of a value such as credential_process,
+// so that they are parsed back as continuation lines
+func formatValue(value, lineBreak string) string {
+	if lineBreak == EmptyString {
+		lineBreak = "\n"
+	}
+
+	return strings.ReplaceAll(value, "\n", lineBreak+"  ")
+}
+
+// unset remove every line of key
+func (s *section) unset(key string) {
+	for i := s.find(key); i >= 0; i = s.find(key) {
+		s.lines = append(s.lines[:i], s.lines[i+1+s.children(i):]...)
+	}
+}
+
+// end returns the position after the last key of the section,
+// so that new keys go before trailing blank lines and comments.
+func (s *section) end() int {
+	for i := len(s.lines); i > 0; i-- {
+		if s.lines[i-1].kind != lineBlank && s.lines[i-1].kind != lineComment {
+			return i
+		}
+	}
+
+	return 0
+}
+
+func (s *section) insert(i int, l *line) {
+	if i > 0 {
+		terminate(s.lines[i-1])
+	} else if s.header != nil {
+		terminate(s.header)
+	}
+
+	s.lines = append(s.lines, nil)
+	copy(s.lines[i+1:], s.lines[i:])
+	s.lines[i] = l
+}
+
+// apply set kvs to the section and unset the known keys which are not in kvs.
+// Keys which are not known are kept as they are.
+func (s *section) apply(kvs []keyValue, known []string) {
+	values := make(map[string]bool)
+	for _, kv := range kvs {
+		values[kv.key] = true
+	}
+
+	for _, key := range known {
+		if !values[key] {
+			s.unset(key)
+		}
+	}
+
+	for _, kv := range kvs {
+		if kv.nested != nil {
+			s.setNested(kv.key, kv.nested)
+		} else {
+			s.set(kv.key, kv.value)
+		}
+	}
+}
+
+// addSection append a section separated by a blank line
+func (d *document) addSection(name string) *section {
+	last := d.sections[len(d.sections)-1]
+
+	if n := len(last.lines); n > 0 {
+		terminate(last.lines[n-1])
+
+		if last.lines[n-1].kind != lineBlank {
+			last.lines = append(last.lines, &line{raw: "\n", kind: lineBlank})
+		}
+	} else if last.header != nil {
+		terminate(last.header)
+		last.lines = append(last.lines, &line{raw: "\n", kind: lineBlank})
+	}
+
+	s := &section{name: name, header: &line{raw: "[" + name + "]\n", kind: lineSection}}
+	d.sections = append(d.sections, s)
+
+	return s
+}
+
+// removeSection remove a section with the comments just above its header.
+// Comments at the end of the section are kept because they usually describe the next section.
+func (d *document) removeSection(s *section) {
+	for i, v := range d.sections {
+		if v != s {
+			continue
+		}
+
+		previous := d.sections[i-1]
+
+		n := len(previous.lines)
+		for n > 0 && previous.lines[n-1].kind == lineComment {
+			n--
+		}
+		previous.lines = previous.lines[:n]
+
+		comment := len(s.lines)
+		for j := len(s.lines); j > 0 && (s.lines[j-1].kind == lineBlank || s.lines[j-1].kind == lineComment); j-- {
+			if s.lines[j-1].kind == lineComment {
+				comment = j - 1
+			}
+		}
+		previous.lines = append(previous.lines, s.lines[comment:]...)
+
+		d.sections = append(d.sections[:i], d.sections[i+1:]...)
+
+		if i == len(d.sections) {
+			for n := len(previous.lines); n > 0 && previous.lines[n-1].kind == lineBlank; n-- {
+				previous.lines = previous.lines[:n-1]
+			}
+		}
+
+		return
+	}
+}
+
+// terminate add a line break to the last line of a file
+func terminate(l *line) {
+	if !strings.HasSuffix(l.raw, "\n") {
+		l.raw += "\n"
+	}
+}
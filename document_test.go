@@ -0,0 +1,275 @@
+package awsprofile_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/youyo/awsprofile"
+)
+
+func copyFile(t *testing.T, src, dst string) {
+	data, err := ioutil.ReadFile(src)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err = ioutil.WriteFile(dst, data, awsprofile.FileMode); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func assertFile(t *testing.T, file, expect string) {
+	data, err := ioutil.ReadFile(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if string(data) != expect {
+		t.Fatalf("Unmatched file\n%s\nexpect\n%s", data, expect)
+	}
+}
+
+func findConfig(configs *awsprofile.Configs, profileName string) awsprofile.Config {
+	for _, config := range configs.List() {
+		if config.ProfileName == profileName {
+			return config
+		}
+	}
+
+	return awsprofile.Config{ProfileName: profileName}
+}
+
+// exported returns the exported fields of v, so that what is kept besides the values such as Origin is ignored
+func exported(v reflect.Value) interface{} {
+	switch v.Kind() {
+	case reflect.Ptr:
+		return exported(v.Elem())
+	case reflect.Slice:
+		var list []interface{}
+		for i := 0; i < v.Len(); i++ {
+			list = append(list, exported(v.Index(i)))
+		}
+		return list
+	case reflect.Struct:
+		if v.Type() == reflect.TypeOf(time.Time{}) {
+			return v.Interface()
+		}
+
+		fields := make(map[string]interface{})
+		for i := 0; i < v.NumField(); i++ {
+			if field := v.Type().Field(i); field.PkgPath == "" {
+				fields[field.Name] = exported(v.Field(i))
+			}
+		}
+		return fields
+	}
+
+	return v.Interface()
+}
+
+func assertExported(t *testing.T, actual, expect interface{}) {
+	if !reflect.DeepEqual(exported(reflect.ValueOf(actual)), exported(reflect.ValueOf(expect))) {
+		t.Fatalf("Unmatched values\n%+v\nexpect\n%+v", actual, expect)
+	}
+}
+
+func TestConfigs_Save_Unchanged(t *testing.T) {
+	dir := tempDir(t)
+	defer os.RemoveAll(dir)
+
+	file := filepath.Join(dir, "config")
+	copyFile(t, "./tests/edit/.aws/config", file)
+
+	configs := awsprofile.NewConfigs()
+	if err := configs.Parse(file); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := configs.Save(file); err != nil {
+		t.Fatal(err)
+	}
+
+	original, _ := ioutil.ReadFile("./tests/edit/.aws/config")
+	assertFile(t, file, string(original))
+}
+
+func TestConfigs_Save_Preserve(t *testing.T) {
+	dir := tempDir(t)
+	defer os.RemoveAll(dir)
+
+	file := filepath.Join(dir, "config")
+	copyFile(t, "./tests/edit/.aws/config", file)
+
+	configs := awsprofile.NewConfigs()
+	if err := configs.Parse(file); err != nil {
+		t.Fatal(err)
+	}
+
+	prod := findConfig(configs, "prod")
+	prod.DurationSeconds = 3600
+	configs.Set(prod)
+
+	legacy := findConfig(configs, "legacy")
+	legacy.Region = ""
+	legacy.Output = "text"
+	configs.Set(legacy)
+
+	configs.Delete("staging")
+	configs.Set(awsprofile.Config{ProfileName: "new", Region: "us-west-2"})
+
+	if err := configs.Save(file); err != nil {
+		t.Fatal(err)
+	}
+
+	assertFile(t, file, `# Managed by hand. Do not reorder.
+
+[default]
+`+"region=ap-northeast-1   \n"+`output = json
+x_team = platform
+
+; production account
+[profile prod]
+role_arn = arn:aws:iam::111111111111:role/admin
+source_profile = default
+# keep the session short
+duration_seconds = 3600
+
+[profile legacy]
+s3 =
+    max_concurrent_requests = 20
+x_note = migrated from v1
+output = text
+
+[profile new]
+region = us-west-2
+`)
+}
+
+func TestCredentials_Save_Preserve(t *testing.T) {
+	dir := tempDir(t)
+	defer os.RemoveAll(dir)
+
+	file := filepath.Join(dir, "credentials")
+	copyFile(t, "./tests/edit/.aws/credentials", file)
+
+	creds := awsprofile.NewCredentials()
+	if err := creds.Parse(file); err != nil {
+		t.Fatal(err)
+	}
+
+	creds.Set(awsprofile.Credential{ProfileName: "default", AwsAccessKeyID: "ACCESS-NEW", AwsSecretAccessKey: "SECRET-NEW"})
+	creds.Delete("old")
+
+	if err := creds.Save(file); err != nil {
+		t.Fatal(err)
+	}
+
+	assertFile(t, file, `# Rotated every 90 days
+[default]
+aws_access_key_id = ACCESS-NEW
+aws_secret_access_key = SECRET-NEW
+`)
+}
+
+func TestConfigs_Parse_NestedComment(t *testing.T) {
+	dir := tempDir(t)
+	defer os.RemoveAll(dir)
+
+	file := filepath.Join(dir, "config")
+	if err := ioutil.WriteFile(file, []byte(`[profile tuned]
+s3 =
+  # tuned
+  max_concurrent_requests = 20
+
+  max_queue_size = 100
+region = us-east-1
+`), awsprofile.FileMode); err != nil {
+		t.Fatal(err)
+	}
+
+	configs := awsprofile.NewConfigs()
+	if err := configs.Parse(file); err != nil {
+		t.Fatal(err)
+	}
+
+	config := findConfig(configs, "tuned")
+	if config.S3.MaxConcurrentRequests != 20 || config.S3.MaxQueueSize != 100 || len(config.Extra) != 0 {
+		t.Fatal("nested keys after a comment or a blank line are lost", config.S3, config.Extra)
+	}
+
+	config.S3.MaxQueueSize = 200
+	configs.Set(config)
+
+	if err := configs.Save(file); err != nil {
+		t.Fatal(err)
+	}
+
+	assertFile(t, file, `[profile tuned]
+s3 =
+  # tuned
+  max_concurrent_requests = 20
+
+  max_queue_size = 200
+region = us-east-1
+`)
+}
+
+func TestConfigs_Parse_OtherSections(t *testing.T) {
+	dir := tempDir(t)
+	defer os.RemoveAll(dir)
+
+	file := filepath.Join(dir, "config")
+	if err := ioutil.WriteFile(file, []byte(`[default]
+region = us-east-1
+
+[preview]
+cloudfront = true
+
+[plugins]
+cli_legacy_plugin_path = /opt/plugins
+
+[profile dev]
+region = ap-northeast-1
+`), awsprofile.FileMode); err != nil {
+		t.Fatal(err)
+	}
+
+	configs := awsprofile.NewConfigs()
+	if err := configs.Parse(file); err != nil {
+		t.Fatal(err)
+	}
+
+	profiles, err := configs.ProfileNames()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(profiles, []string{"default", "dev"}) {
+		t.Fatal("sections other than [default] and [profile name] are profiles", profiles)
+	}
+
+	config := findConfig(configs, "dev")
+	config.Region = "eu-west-1"
+	configs.Set(config)
+
+	if err := configs.Save(file); err != nil {
+		t.Fatal(err)
+	}
+
+	assertFile(t, file, `[default]
+region = us-east-1
+
+[preview]
+cloudfront = true
+
+[plugins]
+cli_legacy_plugin_path = /opt/plugins
+
+[profile dev]
+region = eu-west-1
+`)
+}
@@ -0,0 +1,318 @@
+package awsprofile
+
+import (
+	"errors"
+	"sort"
+)
+
+// constant
+const (
+	DEFAULT_PROFILE string = "default"
+	PROFILE         string = "profile"
+)
+
+// error messages
+var (
+	ErrorPartialCredentials error = errors.New("AWS_SECRET_ACCESS_KEY is not found with AWS_ACCESS_KEY_ID")
+)
+
+// Source is where a setting comes from
+type Source int
+
+// sources of a setting
+const (
+	SourceDefault Source = iota
+	SourceEnv
+	SourceConfigFile
+	SourceCredentialsFile
+	SourceArgument
+)
+
+func (s Source) String() string {
+	switch s {
+	case SourceEnv:
+		return "environment variable"
+	case SourceConfigFile:
+		return "config file"
+	case SourceCredentialsFile:
+		return "credentials file"
+	case SourceArgument:
+		return "argument"
+	}
+
+	return "default"
+}
+
+// Setting is a value of a key and where it comes from.
+// Env is the name of the environment variable when Source is SourceEnv, and Origin is where the key is written in a file.
+// Hops are the profiles of source_profile which the value comes through, e.g. [dev base].
+// Overridden are the values which the setting takes precedence over, in the order of precedence.
+type Setting struct {
+	Key    string
+	Value  string
+	Source Source
+	Env    string
+	Origin
+	Hops       []string
+	Overridden []Setting
+}
+
+// EffectiveProfile provide the settings which the AWS CLI uses.
+// Profile is the selected profile, and Settings are keyed by the keys of config file.
+// Nested keys are joined with a dot, e.g. s3.max_concurrent_requests.
+type EffectiveProfile struct {
+	Profile  Setting
+	Settings map[string]Setting
+}
+
+// effectiveEnv are the environment variables of the keys in the order of precedence
+var effectiveEnv = []struct {
+	key  string
+	envs []string
+}{
+	{REGION, []string{"AWS_REGION", "AWS_DEFAULT_REGION"}},
+	{OUTPUT, []string{"AWS_DEFAULT_OUTPUT"}},
+	{CA_BUNDLE, []string{"AWS_CA_BUNDLE"}},
+	{RETRY_MODE, []string{"AWS_RETRY_MODE"}},
+	{MAX_ATTEMPTS, []string{"AWS_MAX_ATTEMPTS"}},
+	{DEFAULTS_MODE, []string{"AWS_DEFAULTS_MODE"}},
+	{ENDPOINT_URL, []string{"AWS_ENDPOINT_URL"}},
+	{IGNORE_CONFIGURED_ENDPOINT_URLS, []string{"AWS_IGNORE_CONFIGURED_ENDPOINT_URLS"}},
+	{USE_FIPS_ENDPOINT, []string{"AWS_USE_FIPS_ENDPOINT"}},
+	{USE_DUALSTACK_ENDPOINT, []string{"AWS_USE_DUALSTACK_ENDPOINT"}},
+	{STS_REGIONAL_ENDPOINTS, []string{"AWS_STS_REGIONAL_ENDPOINTS"}},
+	{EC2_METADATA_SERVICE_ENDPOINT, []string{"AWS_EC2_METADATA_SERVICE_ENDPOINT"}},
+	{EC2_METADATA_SERVICE_ENDPOINT_MODE, []string{"AWS_EC2_METADATA_SERVICE_ENDPOINT_MODE"}},
+	{METADATA_SERVICE_TIMEOUT, []string{"AWS_METADATA_SERVICE_TIMEOUT"}},
+	{METADATA_SERVICE_NUM_ATTEMPTS, []string{"AWS_METADATA_SERVICE_NUM_ATTEMPTS"}},
+	{CLI_PAGER, []string{"AWS_PAGER"}},
+	{CLI_AUTO_PROMPT, []string{"AWS_CLI_AUTO_PROMPT"}},
+}
+
+// effectiveDefaults are the defaults of the AWS CLI version 2.
+// max_attempts depends on retry_mode, so it is set by setDefaults.
+var effectiveDefaults = map[string]string{
+	OUTPUT:                             "json",
+	RETRY_MODE:                         "standard",
+	DEFAULTS_MODE:                      "legacy",
+	IGNORE_CONFIGURED_ENDPOINT_URLS:    "false",
+	USE_FIPS_ENDPOINT:                  "false",
+	USE_DUALSTACK_ENDPOINT:             "false",
+	STS_REGIONAL_ENDPOINTS:             "regional",
+	EC2_METADATA_SERVICE_ENDPOINT_MODE: "IPv4",
+	METADATA_SERVICE_TIMEOUT:           "1",
+	METADATA_SERVICE_NUM_ATTEMPTS:      "1",
+	PARAMETER_VALIDATION:               "true",
+}
+
+// Effective get the settings which the AWS CLI uses, like Resolve with environment variables layered over the profile.
+//
+// The profile is AWS_DEFAULT_PROFILE, AWS_PROFILE or default, in the order of botocore. The default profile may not exist, but other profiles must.
+// AWS_ACCESS_KEY_ID, AWS_SECRET_ACCESS_KEY and AWS_SESSION_TOKEN replace the credentials of the profile together,
+// and the other environment variables such as AWS_REGION take precedence over the keys of the profile.
+// Keys which are set nowhere have the defaults of the AWS CLI version 2.
+//
+// env is used instead of the environment variables when it is not nil.
+// AWS_SHARED_CREDENTIALS_FILE and AWS_CONFIG_FILE are not read, because they are used by Parse.
+func (a *AwsProfile) Effective(env map[string]string) (*EffectiveProfile, error) {
+	snapshot := a.Snapshot()
+
+	getenv := snapshot.options.getenv
+	if env != nil {
+		getenv = options{env: env}.getenv
+	}
+
+	return snapshot.effective(EmptyString, getenv)
+}
+
+// effective select profile when it is not empty, and the profile of getenv otherwise
+func (a *AwsProfile) effective(profile string, getenv func(string) string) (*EffectiveProfile, error) {
+	effective := &EffectiveProfile{
+		Profile:  Setting{Key: PROFILE, Value: DEFAULT_PROFILE, Source: SourceDefault},
+		Settings: make(map[string]Setting),
+	}
+
+	if profile != EmptyString {
+		effective.Profile = Setting{Key: PROFILE, Value: profile, Source: SourceArgument}
+	} else {
+		for _, name := range []string{"AWS_DEFAULT_PROFILE", "AWS_PROFILE"} {
+			if value := getenv(name); value != EmptyString {
+				effective.Profile = Setting{Key: PROFILE, Value: value, Source: SourceEnv, Env: name}
+				break
+			}
+		}
+	}
+
+	resolved, err := a.Resolve(effective.Profile.Value)
+	switch {
+	case err == nil:
+		effective.setProfile(a, resolved)
+		effective.setSourceProfile(a, resolved.ProfileName)
+	case effective.Profile.Source == SourceDefault && errors.Is(err, ErrorNotFoundProfile):
+		// only environment variables and defaults are used without the default profile
+	default:
+		return nil, err
+	}
+
+	// like --profile of the AWS CLI, a profile of the argument takes precedence over credentials of environment variables
+	if effective.Profile.Source != SourceArgument {
+		if err := effective.setCredentialsEnv(getenv); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, e := range effectiveEnv {
+		for _, name := range e.envs {
+			if value := getenv(name); value != EmptyString {
+				effective.set(Setting{Key: e.key, Value: value, Source: SourceEnv, Env: name})
+				break
+			}
+		}
+	}
+
+	effective.setDefaults()
+
+	return effective, nil
+}
+
+// set a setting which takes precedence over the setting of the same key
+func (e *EffectiveProfile) set(setting Setting) {
+	if previous, ok := e.Settings[setting.Key]; ok {
+		setting.Overridden = append([]Setting{previous}, previous.Overridden...)
+		setting.Overridden[0].Overridden = nil
+	}
+
+	e.Settings[setting.Key] = setting
+}
+
+// setProfile set keys of the config file and the sso-session, overridden by keys of the credentials file
+func (e *EffectiveProfile) setProfile(snapshot *AwsProfile, resolved *ResolvedProfile) {
+	if config, ok := snapshot.Configs.get(resolved.ProfileName); ok {
+		e.setKeyValues(config.keyValues(), SourceConfigFile, config.origins)
+	}
+
+	if resolved.Session != nil {
+		for _, kv := range resolved.Session.keyValues() {
+			if _, ok := e.Settings[kv.key]; !ok && (kv.key == SSO_START_URL || kv.key == SSO_REGION) {
+				origin, _ := resolved.Session.Origin(kv.key)
+				e.set(Setting{Key: kv.key, Value: kv.value, Source: SourceConfigFile, Origin: origin})
+			}
+		}
+	}
+
+	if credential, ok := snapshot.Credentials.get(resolved.ProfileName); ok {
+		e.setKeyValues(credential.keyValues(), SourceCredentialsFile, credential.origins)
+	}
+}
+
+func (e *EffectiveProfile) setKeyValues(kvs []keyValue, source Source, origins origins) {
+	for _, kv := range kvs {
+		if kv.nested == nil {
+			origin, _ := origins.get(kv.key)
+			e.set(Setting{Key: kv.key, Value: kv.value, Source: source, Origin: origin})
+			continue
+		}
+
+		for _, nested := range kv.nested {
+			key := kv.key + "." + nested.key
+			origin, _ := origins.get(key)
+			e.set(Setting{Key: key, Value: nested.value, Source: source, Origin: origin})
+		}
+	}
+}
+
+// setSourceProfile set the credentials which a profile uses to assume a role, when they come through source_profile.
+// The static credentials of the profile itself are overridden, because the AWS CLI assumes the role.
+func (e *EffectiveProfile) setSourceProfile(snapshot *AwsProfile, profile string) {
+	chain, err := snapshot.Chain(profile)
+	if err != nil || len(chain) < 2 || chain[0].Kind != ChainAssumeRole {
+		return
+	}
+
+	last := chain[len(chain)-1]
+	if last.Kind != ChainStaticCredentials || last.ProfileName == profile {
+		return
+	}
+
+	var hops []string
+	for _, link := range chain {
+		hops = append(hops, link.ProfileName)
+	}
+
+	source := &EffectiveProfile{Settings: make(map[string]Setting)}
+	source.setProfile(snapshot, last.ResolvedProfile)
+
+	for _, key := range []string{AwsAccessKeyID, AwsSecretAccessKey, AWS_SESSION_TOKEN} {
+		setting, ok := source.Settings[key]
+		if !ok {
+			continue
+		}
+
+		setting.Hops = hops
+		setting.Overridden = nil
+		e.set(setting)
+	}
+}
+
+// setCredentialsEnv replace the credentials of the profile when AWS_ACCESS_KEY_ID is set.
+// Like the AWS CLI, AWS_SECRET_ACCESS_KEY is required then, and AWS_SECRET_ACCESS_KEY alone is ignored.
+func (e *EffectiveProfile) setCredentialsEnv(getenv func(string) string) error {
+	accessKeyID := getenv("AWS_ACCESS_KEY_ID")
+	if accessKeyID == EmptyString {
+		return nil
+	}
+
+	secretAccessKey := getenv("AWS_SECRET_ACCESS_KEY")
+	if secretAccessKey == EmptyString {
+		return ErrorPartialCredentials
+	}
+
+	e.set(Setting{Key: AwsAccessKeyID, Value: accessKeyID, Source: SourceEnv, Env: "AWS_ACCESS_KEY_ID"})
+	e.set(Setting{Key: AwsSecretAccessKey, Value: secretAccessKey, Source: SourceEnv, Env: "AWS_SECRET_ACCESS_KEY"})
+	delete(e.Settings, AWS_SESSION_TOKEN)
+
+	for _, name := range []string{"AWS_SESSION_TOKEN", "AWS_SECURITY_TOKEN"} {
+		if value := getenv(name); value != EmptyString {
+			e.set(Setting{Key: AWS_SESSION_TOKEN, Value: value, Source: SourceEnv, Env: name})
+			break
+		}
+	}
+
+	return nil
+}
+
+// setDefaults set defaults of keys which are not set.
+// max_attempts is 5 for the legacy retry mode, and 3 for the others.
+func (e *EffectiveProfile) setDefaults() {
+	for key, value := range effectiveDefaults {
+		if _, ok := e.Settings[key]; !ok {
+			e.Settings[key] = Setting{Key: key, Value: value, Source: SourceDefault}
+		}
+	}
+
+	if _, ok := e.Settings[MAX_ATTEMPTS]; !ok {
+		maxAttempts := "3"
+		if e.Settings[RETRY_MODE].Value == "legacy" {
+			maxAttempts = "5"
+		}
+
+		e.Settings[MAX_ATTEMPTS] = Setting{Key: MAX_ATTEMPTS, Value: maxAttempts, Source: SourceDefault}
+	}
+}
+
+// Get get a setting. ok is false when the key is set nowhere and has no default.
+func (e *EffectiveProfile) Get(key string) (Setting, bool) {
+	setting, ok := e.Settings[key]
+	return setting, ok
+}
+
+// Keys get keys of settings in sorted order
+func (e *EffectiveProfile) Keys() []string {
+	var keys []string
+	for key := range e.Settings {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	return keys
+}
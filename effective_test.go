@@ -0,0 +1,184 @@
+package awsprofile_test
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"testing"
+	"testing/fstest"
+
+	"github.com/youyo/awsprofile"
+)
+
+var effectiveFS = fstest.MapFS{
+	".aws/credentials": {Data: []byte(`[default]
+aws_access_key_id = AKIADEFAULT
+aws_secret_access_key = default-secret
+
+[dev]
+aws_access_key_id = AKIADEV
+aws_secret_access_key = dev-secret
+aws_session_token = dev-token
+`)},
+	".aws/config": {Data: []byte(`[default]
+region = us-east-1
+
+[profile dev]
+region = ap-northeast-1
+output = text
+retry_mode = legacy
+s3 =
+  max_concurrent_requests = 20
+`)},
+}
+
+func newEffectiveProfile(t *testing.T, fsys fstest.MapFS) *awsprofile.AwsProfile {
+	awsProfile := awsprofile.New(awsprofile.WithFS(fsys), awsprofile.WithEnv(map[string]string{}))
+	if err := awsProfile.Parse(); err != nil {
+		t.Fatal(err)
+	}
+
+	return awsProfile
+}
+
+func ExampleAwsProfile_Effective() {
+	awsProfile := awsprofile.New(awsprofile.WithFS(effectiveFS), awsprofile.WithEnv(map[string]string{}))
+	if err := awsProfile.Parse(); err != nil {
+		log.Fatal(err)
+	}
+
+	effective, err := awsProfile.Effective(map[string]string{
+		"AWS_PROFILE": "dev",
+		"AWS_REGION":  "eu-west-1",
+	})
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	fmt.Println(effective.Profile.Value, effective.Profile.Env)
+	for _, key := range []string{"region", "output", "aws_access_key_id", "max_attempts"} {
+		setting, _ := effective.Get(key)
+		fmt.Println(key, setting.Value, setting.Source)
+	}
+	// Output:
+	// dev AWS_PROFILE
+	// region eu-west-1 environment variable
+	// output text config file
+	// aws_access_key_id AKIADEV credentials file
+	// max_attempts 5 default
+}
+
+func TestAwsProfile_Effective(t *testing.T) {
+	awsProfile := newEffectiveProfile(t, effectiveFS)
+
+	tests := []struct {
+		name   string
+		env    map[string]string
+		key    string
+		value  string
+		source awsprofile.Source
+		envKey string
+	}{
+		{"default profile", map[string]string{}, "region", "us-east-1", awsprofile.SourceConfigFile, ""},
+		{"AWS_DEFAULT_PROFILE", map[string]string{"AWS_DEFAULT_PROFILE": "dev"}, "region", "ap-northeast-1", awsprofile.SourceConfigFile, ""},
+		{"AWS_REGION over AWS_DEFAULT_REGION", map[string]string{"AWS_REGION": "eu-west-1", "AWS_DEFAULT_REGION": "eu-west-2"}, "region", "eu-west-1", awsprofile.SourceEnv, "AWS_REGION"},
+		{"AWS_DEFAULT_REGION", map[string]string{"AWS_DEFAULT_REGION": "eu-west-2"}, "region", "eu-west-2", awsprofile.SourceEnv, "AWS_DEFAULT_REGION"},
+		{"nested key", map[string]string{"AWS_PROFILE": "dev"}, "s3.max_concurrent_requests", "20", awsprofile.SourceConfigFile, ""},
+		{"session token of credentials file", map[string]string{"AWS_PROFILE": "dev"}, "aws_session_token", "dev-token", awsprofile.SourceCredentialsFile, ""},
+		{"AWS_MAX_ATTEMPTS", map[string]string{"AWS_MAX_ATTEMPTS": "10"}, "max_attempts", "10", awsprofile.SourceEnv, "AWS_MAX_ATTEMPTS"},
+		{"default max_attempts", map[string]string{}, "max_attempts", "3", awsprofile.SourceDefault, ""},
+		{"default output", map[string]string{}, "output", "json", awsprofile.SourceDefault, ""},
+		{"AWS_CA_BUNDLE", map[string]string{"AWS_CA_BUNDLE": "/etc/ca.pem"}, "ca_bundle", "/etc/ca.pem", awsprofile.SourceEnv, "AWS_CA_BUNDLE"},
+		{"secret key alone is ignored", map[string]string{"AWS_SECRET_ACCESS_KEY": "env-secret"}, "aws_secret_access_key", "default-secret", awsprofile.SourceCredentialsFile, ""},
+	}
+
+	for _, tt := range tests {
+		effective, err := awsProfile.Effective(tt.env)
+		if err != nil {
+			t.Fatal(tt.name, err)
+		}
+
+		setting, ok := effective.Get(tt.key)
+		if !ok || setting.Value != tt.value || setting.Source != tt.source || setting.Env != tt.envKey {
+			t.Error(tt.name, setting)
+		}
+	}
+}
+
+func TestAwsProfile_Effective_CredentialsEnv(t *testing.T) {
+	awsProfile := newEffectiveProfile(t, effectiveFS)
+
+	effective, err := awsProfile.Effective(map[string]string{
+		"AWS_PROFILE":           "dev",
+		"AWS_ACCESS_KEY_ID":     "AKIAENV",
+		"AWS_SECRET_ACCESS_KEY": "env-secret",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if setting, _ := effective.Get("aws_access_key_id"); setting.Value != "AKIAENV" || setting.Env != "AWS_ACCESS_KEY_ID" {
+		t.Error("Unmatched aws_access_key_id", setting)
+	}
+
+	// the session token of the profile is not used with credentials of environment variables
+	if setting, ok := effective.Get("aws_session_token"); ok {
+		t.Error("Unexpected aws_session_token", setting)
+	}
+
+	effective, err = awsProfile.Effective(map[string]string{
+		"AWS_ACCESS_KEY_ID":     "AKIAENV",
+		"AWS_SECRET_ACCESS_KEY": "env-secret",
+		"AWS_SECURITY_TOKEN":    "env-token",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if setting, _ := effective.Get("aws_session_token"); setting.Value != "env-token" || setting.Env != "AWS_SECURITY_TOKEN" {
+		t.Error("Unmatched aws_session_token", setting)
+	}
+
+	if _, err := awsProfile.Effective(map[string]string{"AWS_ACCESS_KEY_ID": "AKIAENV"}); err != awsprofile.ErrorPartialCredentials {
+		t.Error("Unexpected error", err)
+	}
+}
+
+func TestAwsProfile_Effective_Profile(t *testing.T) {
+	awsProfile := newEffectiveProfile(t, fstest.MapFS{
+		".aws/config": {Data: []byte("[profile dev]\nregion = ap-northeast-1\n")},
+	})
+
+	// the default profile may not exist
+	effective, err := awsProfile.Effective(map[string]string{"AWS_DEFAULT_REGION": "us-west-2"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if effective.Profile.Value != "default" || effective.Profile.Source != awsprofile.SourceDefault {
+		t.Error("Unexpected profile", effective.Profile)
+	}
+
+	if setting, _ := effective.Get("region"); setting.Value != "us-west-2" {
+		t.Error("Unmatched region", setting)
+	}
+
+	if fmt.Sprint(effective.Keys()[:2]) != "[defaults_mode ec2_metadata_service_endpoint_mode]" {
+		t.Error("Unexpected keys", effective.Keys())
+	}
+
+	// a profile of environment variables must exist
+	if _, err := awsProfile.Effective(map[string]string{"AWS_PROFILE": "prod"}); !errors.Is(err, awsprofile.ErrorNotFoundProfile) {
+		t.Error("Unexpected error", err)
+	}
+
+	// AWS_DEFAULT_PROFILE takes precedence over AWS_PROFILE
+	effective, err = awsProfile.Effective(map[string]string{"AWS_PROFILE": "prod", "AWS_DEFAULT_PROFILE": "dev"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if effective.Profile.Value != "dev" || effective.Profile.Env != "AWS_DEFAULT_PROFILE" {
+		t.Error("Unexpected profile", effective.Profile)
+	}
+}
@@ -0,0 +1,293 @@
+package awsprofile
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ParseError is an error of a line of credentials file or config file.
+// Key of a nested key is joined with a dot, e.g. s3.max_concurrent_requests.
+type ParseError struct {
+	Path    string
+	Line    int
+	Profile string
+	Key     string
+	Err     error
+}
+
+func (e *ParseError) Error() string {
+	var location []string
+
+	if e.Path != EmptyString {
+		location = append(location, e.Path)
+	}
+	if e.Line != ZeroInt {
+		location = append(location, strconv.Itoa(e.Line))
+	}
+
+	var message []string
+
+	if len(location) > 0 {
+		message = append(message, strings.Join(location, ":"))
+	}
+	if e.Profile != EmptyString {
+		message = append(message, "profile "+e.Profile)
+	}
+	if e.Key != EmptyString {
+		message = append(message, e.Key)
+	}
+
+	return strings.Join(append(message, e.Err.Error()), ": ")
+}
+
+// Unwrap returns the underlying error, e.g. *strconv.NumError
+func (e *ParseError) Unwrap() error {
+	return e.Err
+}
+
+// ParseErrors has all errors found by ParseLenient in line order
+type ParseErrors []*ParseError
+
+func (e ParseErrors) Error() string {
+	var messages []string
+
+	for _, err := range e {
+		messages = append(messages, err.Error())
+	}
+
+	return strings.Join(messages, "\n")
+}
+
+func (e ParseErrors) setPath(path string) {
+	for _, err := range e {
+		err.Path = path
+	}
+}
+
+// err returns nil for no errors, so that a nil ParseErrors is not a non-nil error
+func (e ParseErrors) err() error {
+	if len(e) == 0 {
+		return nil
+	}
+
+	return e
+}
+
+// parseFile read filename and parse it by parse
+func parseFile(filename string, lenient bool, parse func(data []byte, source string, lenient bool) error) error {
+	data, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return err
+	}
+
+	return parse(data, filename, lenient)
+}
+
+// parseReader read r to the end and parse it by parse
+func parseReader(r io.Reader, source string, lenient bool, parse func(data []byte, source string, lenient bool) error) error {
+	data, err := ioutil.ReadAll(r)
+	if err != nil {
+		return err
+	}
+
+	return parse(data, source, lenient)
+}
+
+// parseSource parse data read from source, which is the path of a file or the name given to ParseBytes and ParseReader.
+// Errors of lines are returned as errs when lenient, otherwise the first one is returned as err.
+func parseSource(data []byte, source string, lenient bool) (*document, ParseErrors, error) {
+	d, errs := parseDocument(data)
+	errs.setPath(source)
+
+	if errs != nil && !lenient {
+		return nil, nil, errs[0]
+	}
+
+	return d, errs, nil
+}
+
+// skipProfile add a profile which has errors to skipped
+func skipProfile(skipped map[string]bool, profileName string) map[string]bool {
+	if skipped == nil {
+		skipped = make(map[string]bool)
+	}
+
+	skipped[profileName] = true
+
+	return skipped
+}
+
+// appendParseErrors append ParseErrors of err to errs, or returns err which is not ParseErrors
+func appendParseErrors(errs ParseErrors, err error) (ParseErrors, error) {
+	if err == nil {
+		return errs, nil
+	}
+
+	if e, ok := err.(ParseErrors); ok {
+		return append(errs, e...), nil
+	}
+
+	return errs, err
+}
+
+// sortByLine sort errors of a file in line order
+func (e ParseErrors) sortByLine() {
+	sort.SliceStable(e, func(i, j int) bool {
+		return e[i].Line < e[j].Line
+	})
+}
+
+// NotFoundError is an error of a profile which is not found, or a key which is not set in a profile.
+// Key is empty when the profile is not found, and then Suggestions has profile names similar to Profile.
+// Section and Name are set when a sso-session or services section is not found, e.g. sso-session and my-sso,
+// and then Suggestions has names of the sections similar to Name, and Profile is the profile which refers it or empty.
+//
+// It matches ErrorNotFoundProfile by errors.Is when the profile is not found,
+// ErrorNotFoundSSOSessionSection or ErrorNotFoundServicesSection when the section is not found,
+// and the error which was returned before NotFoundError, e.g. ErrorNotFoundRegion.
+type NotFoundError struct {
+	Profile     string
+	Key         string
+	Section     string
+	Name        string
+	Suggestions []string
+	err         error
+}
+
+func (e *NotFoundError) Error() string {
+	if e.Key != EmptyString {
+		return fmt.Sprintf("%s%s in profile %q", e.Key, ErrorNotFound, e.Profile)
+	}
+
+	message := fmt.Sprintf("profile %q%s", e.Profile, ErrorNotFound)
+
+	if e.Section != EmptyString {
+		message = fmt.Sprintf("%s section %q%s", e.Section, e.Name, ErrorNotFound)
+
+		if e.Profile != EmptyString {
+			message += fmt.Sprintf(" for profile %q", e.Profile)
+		}
+	}
+
+	if len(e.Suggestions) > 0 {
+		var quoted []string
+		for _, suggestion := range e.Suggestions {
+			quoted = append(quoted, strconv.Quote(suggestion))
+		}
+
+		message += ", did you mean " + strings.Join(quoted, " or ") + "?"
+	}
+
+	return message
+}
+
+// Is reports whether target is ErrorNotFoundProfile for a profile which is not found,
+// the error of the section which is not found, or the error which was returned before NotFoundError.
+func (e *NotFoundError) Is(target error) bool {
+	switch {
+	case e.Section == SSO_SESSION_SECTION && target == ErrorNotFoundSSOSessionSection,
+		e.Section == SERVICES_SECTION && target == ErrorNotFoundServicesSection,
+		e.Section == EmptyString && e.Key == EmptyString && target == ErrorNotFoundProfile:
+		return true
+	}
+
+	return e.err != nil && target == e.err
+}
+
+// profileNotFound returns NotFoundError of profile with suggestions from names.
+// err is the error which was returned before NotFoundError.
+func profileNotFound(profile string, names []string, err error) error {
+	return &NotFoundError{Profile: profile, Suggestions: suggest(profile, names), err: err}
+}
+
+// sectionNotFound returns NotFoundError of a section of kind with suggestions from names.
+// profile refers the section, or is empty. err is the error which was returned before NotFoundError.
+func sectionNotFound(kind, name, profile string, names []string, err error) error {
+	return &NotFoundError{Profile: profile, Section: kind, Name: name, Suggestions: suggest(name, names), err: err}
+}
+
+// keyNotFound returns NotFoundError of key which is not set in profile.
+// err is the error which was returned before NotFoundError.
+func keyNotFound(profile, key string, err error) error {
+	return &NotFoundError{Profile: profile, Key: key, err: err}
+}
+
+// suggest returns names similar to name in the order of similarity.
+// A name is similar when the edit distance is within a third of the length of name, ignoring case.
+func suggest(name string, names []string) []string {
+	type candidate struct {
+		name     string
+		distance int
+	}
+
+	threshold := len(name) / 3
+	if threshold < 1 {
+		threshold = 1
+	}
+
+	var candidates []candidate
+
+	for _, n := range removeDuplicate(names) {
+		if n == name {
+			continue
+		}
+
+		if distance := editDistance(strings.ToLower(name), strings.ToLower(n)); distance <= threshold {
+			candidates = append(candidates, candidate{name: n, distance: distance})
+		}
+	}
+
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].distance < candidates[j].distance
+	})
+
+	var suggestions []string
+	for _, c := range candidates {
+		suggestions = append(suggestions, c.name)
+	}
+
+	return suggestions
+}
+
+// editDistance returns the Levenshtein distance of a and b
+func editDistance(a, b string) int {
+	s, t := []rune(a), []rune(b)
+
+	previous := make([]int, len(t)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+
+	for i := 1; i <= len(s); i++ {
+		current := make([]int, len(t)+1)
+		current[0] = i
+
+		for j := 1; j <= len(t); j++ {
+			cost := 1
+			if s[i-1] == t[j-1] {
+				cost = 0
+			}
+
+			current[j] = minInt(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
+		}
+
+		previous = current
+	}
+
+	return previous[len(t)]
+}
+
+func minInt(values ...int) int {
+	m := values[0]
+	for _, v := range values[1:] {
+		if v < m {
+			m = v
+		}
+	}
+
+	return m
+}
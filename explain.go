@@ -0,0 +1,87 @@
+package awsprofile
+
+import (
+	"strings"
+)
+
+// secretKeys are masked by Explain and String of Setting
+var secretKeys = map[string]bool{
+	AwsSecretAccessKey: true,
+	AWS_SESSION_TOKEN:  true,
+}
+
+// Explain get the decision trail of the settings of a profile, which tells where each value comes from
+// and which values it takes precedence over. The profile is selected like Effective when profile is empty,
+// and environment variables are the ones of New. Like --profile of the AWS CLI, profile which is not empty
+// is not replaced by AWS_ACCESS_KEY_ID and AWS_SECRET_ACCESS_KEY.
+func (a *AwsProfile) Explain(profile string) (string, error) {
+	snapshot := a.Snapshot()
+
+	effective, err := snapshot.effective(profile, snapshot.options.getenv)
+	if err != nil {
+		return EmptyString, err
+	}
+
+	return effective.Explain(), nil
+}
+
+// Explain get the decision trail of the settings in sorted order of keys. e.g.
+//
+//	profile = dev (environment variable AWS_PROFILE)
+//	region = eu-west-1 (environment variable AWS_REGION)
+//	  overrides ap-northeast-1 (config file /home/foo/.aws/config:5 [profile dev])
+func (e *EffectiveProfile) Explain() string {
+	var b strings.Builder
+
+	b.WriteString(e.Profile.Key + " = " + e.Profile.String() + "\n")
+
+	for _, key := range e.Keys() {
+		setting := e.Settings[key]
+
+		b.WriteString(key + " = " + setting.String() + "\n")
+
+		for _, overridden := range setting.Overridden {
+			b.WriteString("  overrides " + overridden.String() + "\n")
+		}
+	}
+
+	return b.String()
+}
+
+// String get the value and where it comes from. Secrets such as aws_secret_access_key are masked.
+func (s Setting) String() string {
+	return s.maskedValue() + " (" + s.where() + ")"
+}
+
+func (s Setting) maskedValue() string {
+	if !secretKeys[s.Key] {
+		return s.Value
+	}
+
+	if len(s.Value) <= 4 {
+		return "****"
+	}
+
+	return "****" + s.Value[len(s.Value)-4:]
+}
+
+func (s Setting) where() string {
+	switch s.Source {
+	case SourceEnv:
+		return s.Source.String() + " " + s.Env
+	case SourceConfigFile, SourceCredentialsFile:
+		where := s.Source.String()
+
+		if s.Origin != (Origin{}) {
+			where += " " + s.Origin.String()
+		}
+
+		if s.Hops != nil {
+			where += " via source_profile " + strings.Join(s.Hops, " -> ")
+		}
+
+		return where
+	}
+
+	return s.Source.String()
+}
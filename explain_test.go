@@ -0,0 +1,150 @@
+package awsprofile_test
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/youyo/awsprofile"
+)
+
+var explainFS = fstest.MapFS{
+	".aws/credentials": {Data: []byte(`[base]
+aws_access_key_id = AKIABASE
+aws_secret_access_key = base-secret
+
+[dev]
+aws_access_key_id = AKIADEV
+aws_secret_access_key = dev-secret
+`)},
+	".aws/config": {Data: []byte(`[profile base]
+region = us-east-1
+
+[profile dev]
+role_arn = arn:aws:iam::123456789012:role/dev
+source_profile = base
+region = ap-northeast-1
+`)},
+}
+
+func ExampleAwsProfile_Explain() {
+	awsProfile := awsprofile.New(
+		awsprofile.WithFS(explainFS),
+		awsprofile.WithEnv(map[string]string{"AWS_REGION": "eu-west-1"}),
+	)
+	if err := awsProfile.Parse(); err != nil {
+		log.Fatal(err)
+	}
+
+	explain, err := awsProfile.Explain("dev")
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	fmt.Print(explain)
+	// Output:
+	// profile = dev (argument)
+	// aws_access_key_id = AKIABASE (credentials file .aws/credentials:2 [base] via source_profile dev -> base)
+	//   overrides AKIADEV (credentials file .aws/credentials:6 [dev])
+	// aws_secret_access_key = ****cret (credentials file .aws/credentials:3 [base] via source_profile dev -> base)
+	//   overrides ****cret (credentials file .aws/credentials:7 [dev])
+	// defaults_mode = legacy (default)
+	// ec2_metadata_service_endpoint_mode = IPv4 (default)
+	// ignore_configured_endpoint_urls = false (default)
+	// max_attempts = 3 (default)
+	// metadata_service_num_attempts = 1 (default)
+	// metadata_service_timeout = 1 (default)
+	// output = json (default)
+	// parameter_validation = true (default)
+	// region = eu-west-1 (environment variable AWS_REGION)
+	//   overrides ap-northeast-1 (config file .aws/config:7 [profile dev])
+	// retry_mode = standard (default)
+	// role_arn = arn:aws:iam::123456789012:role/dev (config file .aws/config:5 [profile dev])
+	// source_profile = base (config file .aws/config:6 [profile dev])
+	// sts_regional_endpoints = regional (default)
+	// use_dualstack_endpoint = false (default)
+	// use_fips_endpoint = false (default)
+}
+
+func TestAwsProfile_Explain(t *testing.T) {
+	awsProfile := awsprofile.New(
+		awsprofile.WithFS(explainFS),
+		awsprofile.WithEnv(map[string]string{"AWS_PROFILE": "base", "AWS_SESSION_TOKEN": "token"}),
+	)
+	if err := awsProfile.Parse(); err != nil {
+		t.Fatal(err)
+	}
+
+	explain, err := awsProfile.Explain("")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, line := range []string{
+		"profile = base (environment variable AWS_PROFILE)\n",
+		"aws_access_key_id = AKIABASE (credentials file .aws/credentials:2 [base])\n",
+		"region = us-east-1 (config file .aws/config:2 [profile base])\n",
+	} {
+		if !strings.Contains(explain, line) {
+			t.Error("line is not found", line, explain)
+		}
+	}
+
+	// AWS_SESSION_TOKEN is used only with AWS_ACCESS_KEY_ID and AWS_SECRET_ACCESS_KEY
+	if strings.Contains(explain, "aws_session_token") {
+		t.Error("Unexpected aws_session_token", explain)
+	}
+
+	if _, err := awsProfile.Explain("nothing"); err == nil {
+		t.Error("Explain should fail for a profile which is not found")
+	}
+}
+
+func TestAwsProfile_Explain_CredentialsEnv(t *testing.T) {
+	awsProfile := awsprofile.New(
+		awsprofile.WithFS(explainFS),
+		awsprofile.WithEnv(map[string]string{"AWS_ACCESS_KEY_ID": "AKIAENV", "AWS_SECRET_ACCESS_KEY": "env-secret"}),
+	)
+	if err := awsProfile.Parse(); err != nil {
+		t.Fatal(err)
+	}
+
+	// credentials of environment variables are not used for a profile of the argument
+	explain, err := awsProfile.Explain("base")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !strings.Contains(explain, "aws_access_key_id = AKIABASE (credentials file .aws/credentials:2 [base])\n") || strings.Contains(explain, "AKIAENV") {
+		t.Error("Unexpected aws_access_key_id", explain)
+	}
+
+	explain, err = awsProfile.Explain("")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !strings.Contains(explain, "aws_access_key_id = AKIAENV (environment variable AWS_ACCESS_KEY_ID)\n") {
+		t.Error("Unexpected aws_access_key_id", explain)
+	}
+}
+
+func TestSetting_String(t *testing.T) {
+	tests := []struct {
+		setting awsprofile.Setting
+		expect  string
+	}{
+		{awsprofile.Setting{Key: "region", Value: "us-east-1", Source: awsprofile.SourceEnv, Env: "AWS_DEFAULT_REGION"}, "us-east-1 (environment variable AWS_DEFAULT_REGION)"},
+		{awsprofile.Setting{Key: "aws_session_token", Value: "abc", Source: awsprofile.SourceCredentialsFile}, "**** (credentials file)"},
+		{awsprofile.Setting{Key: "output", Value: "json"}, "json (default)"},
+		{awsprofile.Setting{Key: "region", Value: "eu-west-1", Source: awsprofile.SourceConfigFile, Origin: awsprofile.Origin{Path: "stdin", Line: 3}}, "eu-west-1 (config file stdin:3)"},
+	}
+
+	for _, tt := range tests {
+		if tt.setting.String() != tt.expect {
+			t.Error("Unmatched String", tt.setting.String(), "expect", tt.expect)
+		}
+	}
+}
@@ -0,0 +1,97 @@
+package awsprofile
+
+// Get get a value of any key of a profile, including keys which have no field.
+// NotFoundError is returned when the profile is not found or the profile does not have the key.
+func (c *Configs) Get(profileName, key string) (string, error) {
+	config, ok := c.get(profileName)
+	if !ok {
+		return EmptyString, c.notFound(profileName, ErrorNotFoundProfile)
+	}
+
+	value, ok := lookupKeyValue(config.keyValues(), key)
+	if !ok {
+		return EmptyString, keyNotFound(profileName, key, nil)
+	}
+
+	return value, nil
+}
+
+// Get get a value of any key, including keys which have no field
+func (c *Config) Get(key string) string {
+	value, _ := lookupKeyValue(c.keyValues(), key)
+	return value
+}
+
+// GetExtra get keys which have no field
+func (c *Config) GetExtra() map[string]string {
+	return c.Extra
+}
+
+// Get get a value of any key of a profile, including keys which have no field.
+// NotFoundError is returned when the profile is not found or the profile does not have the key.
+func (c *Credentials) Get(profileName, key string) (string, error) {
+	credential, ok := c.get(profileName)
+	if !ok {
+		return EmptyString, c.notFound(profileName, ErrorNotFoundProfile)
+	}
+
+	value, ok := lookupKeyValue(credential.keyValues(), key)
+	if !ok {
+		return EmptyString, keyNotFound(profileName, key, nil)
+	}
+
+	return value, nil
+}
+
+// Get get a value of any key, including keys which have no field
+func (c *Credential) Get(key string) string {
+	value, _ := lookupKeyValue(c.keyValues(), key)
+	return value
+}
+
+// GetExtra get keys which have no field
+func (c *Credential) GetExtra() map[string]string {
+	return c.Extra
+}
+
+// setExtra keep a key which has no field
+func setExtra(extra *map[string]string, key, value string) {
+	if *extra == nil {
+		*extra = make(map[string]string)
+	}
+
+	(*extra)[key] = value
+}
+
+// appendExtra append keys which have no field in sorted order.
+// Unlike fields, keys with an empty value are written as they are,
+// and keys which are already in kvs are skipped.
+func appendExtra(kvs []keyValue, extra map[string]string) []keyValue {
+	for _, kv := range sortedKeyValues(extra) {
+		if !hasKeyValue(kvs, kv.key) {
+			kvs = append(kvs, kv)
+		}
+	}
+
+	return kvs
+}
+
+func hasKeyValue(kvs []keyValue, key string) bool {
+	for _, kv := range kvs {
+		if kv.key == key {
+			return true
+		}
+	}
+
+	return false
+}
+
+func lookupKeyValue(kvs []keyValue, key string) (string, bool) {
+	for _, kv := range kvs {
+		if kv.key == key {
+			return kv.value, true
+		}
+	}
+
+	return EmptyString, false
+}
@@ -1,8 +1,5 @@
 module github.com/youyo/awsprofile
 
-go 1.13
+go 1.16
 
-require (
-	github.com/mitchellh/go-homedir v1.1.0
-	gopkg.in/ini.v1 v1.49.0
-)
+require github.com/mitchellh/go-homedir v1.1.0
@@ -0,0 +1,505 @@
+package awsprofile
+
+import (
+	"strings"
+	"time"
+)
+
+// Lookup get a value of any key as it is written, and reports whether the key is set.
+// A key is set when it is in the file, even if the value is zero or empty, or when the field has a non-zero value.
+func (c *Config) Lookup(key string) (string, bool) {
+	return lookupKeyValue(c.keyValues(), key)
+}
+
+// Unset remove a key, so that Save removes the key from the file.
+// A nested key is joined with a dot, e.g. s3.max_queue_size.
+func (c *Config) Unset(key string) {
+	if _, ok := c.Extra[key]; !ok && strings.Contains(key, ".") {
+		i := strings.Index(key, ".")
+		c.unsetNested(key[:i], key[i+1:])
+		return
+	}
+
+	c.present = withoutKey(c.present, key)
+	c.Extra = withoutString(c.Extra, key)
+	c.Nested = withoutNestedValues(c.Nested, key, EmptyString)
+
+	switch key {
+	case AwsAccessKeyID:
+		c.AwsAccessKeyID = EmptyString
+	case AwsSecretAccessKey:
+		c.AwsSecretAccessKey = EmptyString
+	case ROLE_ARN:
+		c.RoleArn = EmptyString
+	case SOURCE_PROFILE:
+		c.SourceProfile = EmptyString
+	case CREDENTIAL_SOURCE:
+		c.CredentialSource = EmptyString
+	case ROLE_SESSION_NAME:
+		c.RoleSessionName = EmptyString
+	case MFA_SERIAL:
+		c.MfaSerial = EmptyString
+	case DURATION_SECONDS:
+		c.DurationSeconds = ZeroInt
+	case AWS_SESSION_TOKEN:
+		c.AwsSessionToken = EmptyString
+	case EXTERNAL_ID:
+		c.ExternalID = EmptyString
+	case CA_BUNDLE:
+		c.CaBundle = EmptyString
+	case CLI_FOLLOW_URLPARAM:
+		c.CliFollowUrlparam = EmptyString
+	case CLI_TIMESTAMP_FORMAT:
+		c.CliTimestampFormat = EmptyString
+	case CREDENTIAL_PROCESS:
+		c.CredentialProcess = EmptyString
+	case WEB_IDENTITY_TOKEN_FILE:
+		c.WebIdentityTokenFile = EmptyString
+	case OUTPUT:
+		c.Output = EmptyString
+	case REGION:
+		c.Region = EmptyString
+	case SSO_START_URL:
+		c.SSOStartURL = EmptyString
+	case SSO_REGION:
+		c.SSORegion = EmptyString
+	case SSO_ACCOUNT_ID:
+		c.SSOAccountID = EmptyString
+	case SSO_ROLE_NAME:
+		c.SSORoleName = EmptyString
+	case SSO_SESSION:
+		c.SSOSession = EmptyString
+	case SERVICES:
+		c.Services = EmptyString
+	case ENDPOINT_URL:
+		c.EndpointURL = EmptyString
+	case IGNORE_CONFIGURED_ENDPOINT_URLS:
+		c.IgnoreConfiguredEndpointURLs = false
+	case RETRY_MODE:
+		c.RetryMode = EmptyString
+	case MAX_ATTEMPTS:
+		c.MaxAttempts = ZeroInt
+	case DEFAULTS_MODE:
+		c.DefaultsMode = EmptyString
+	case USE_FIPS_ENDPOINT:
+		c.UseFIPSEndpoint = false
+	case USE_DUALSTACK_ENDPOINT:
+		c.UseDualstackEndpoint = false
+	case STS_REGIONAL_ENDPOINTS:
+		c.STSRegionalEndpoints = EmptyString
+	case EC2_METADATA_SERVICE_ENDPOINT:
+		c.EC2MetadataServiceEndpoint = EmptyString
+	case EC2_METADATA_SERVICE_ENDPOINT_MODE:
+		c.EC2MetadataServiceEndpointMode = EmptyString
+	case METADATA_SERVICE_TIMEOUT:
+		c.MetadataServiceTimeout = ZeroInt
+	case METADATA_SERVICE_NUM_ATTEMPTS:
+		c.MetadataServiceNumAttempts = ZeroInt
+	case CLI_PAGER:
+		c.CliPager = EmptyString
+	case CLI_AUTO_PROMPT:
+		c.CliAutoPrompt = EmptyString
+	case PARAMETER_VALIDATION:
+		c.ParameterValidation = false
+	case TCP_KEEPALIVE:
+		c.TCPKeepalive = false
+	case SOURCE_IDENTITY:
+		c.SourceIdentity = EmptyString
+	case S3:
+		c.S3 = S3Settings{}
+		c.present = withoutNested(c.present, key)
+	case S3API:
+		c.S3API = S3Settings{}
+		c.present = withoutNested(c.present, key)
+	}
+}
+
+// unsetNested remove a nested key of parent
+func (c *Config) unsetNested(parent, key string) {
+	c.present = withoutKey(c.present, parent+"."+key)
+
+	c.Nested = withoutNestedValues(c.Nested, parent, key)
+
+	switch parent {
+	case S3:
+		c.S3.unset(key)
+	case S3API:
+		c.S3API.unset(key)
+	}
+}
+
+// setPresent mark key is in the file
+func (c *Config) setPresent(key string) {
+	if c.present == nil {
+		c.present = make(map[string]bool)
+	}
+
+	c.present[key] = true
+}
+
+// setWritten keep the value of key as written in the file, so that Save does not rewrite an unchanged value
+func (c *Config) setWritten(key, value string) {
+	if c.written == nil {
+		c.written = make(map[string]string)
+	}
+
+	c.written[key] = value
+}
+
+// LookupAwsAccessKeyID get aws_access_key_id, and reports whether it is set
+func (c *Config) LookupAwsAccessKeyID() (string, bool) {
+	_, ok := c.Lookup(AwsAccessKeyID)
+	return c.AwsAccessKeyID, ok
+}
+
+// LookupAwsSecretAccessKey get aws_secret_access_key, and reports whether it is set
+func (c *Config) LookupAwsSecretAccessKey() (string, bool) {
+	_, ok := c.Lookup(AwsSecretAccessKey)
+	return c.AwsSecretAccessKey, ok
+}
+
+// LookupRoleArn get role_arn, and reports whether it is set
+func (c *Config) LookupRoleArn() (string, bool) {
+	_, ok := c.Lookup(ROLE_ARN)
+	return c.RoleArn, ok
+}
+
+// LookupSourceProfile get source_profile, and reports whether it is set
+func (c *Config) LookupSourceProfile() (string, bool) {
+	_, ok := c.Lookup(SOURCE_PROFILE)
+	return c.SourceProfile, ok
+}
+
+// LookupCredentialSource get credential_source, and reports whether it is set
+func (c *Config) LookupCredentialSource() (string, bool) {
+	_, ok := c.Lookup(CREDENTIAL_SOURCE)
+	return c.CredentialSource, ok
+}
+
+// LookupRoleSessionName get role_session_name, and reports whether it is set
+func (c *Config) LookupRoleSessionName() (string, bool) {
+	_, ok := c.Lookup(ROLE_SESSION_NAME)
+	return c.RoleSessionName, ok
+}
+
+// LookupMfaSerial get mfa_serial, and reports whether it is set
+func (c *Config) LookupMfaSerial() (string, bool) {
+	_, ok := c.Lookup(MFA_SERIAL)
+	return c.MfaSerial, ok
+}
+
+// LookupDurationSeconds get duration_seconds, and reports whether it is set
+func (c *Config) LookupDurationSeconds() (int, bool) {
+	_, ok := c.Lookup(DURATION_SECONDS)
+	return c.DurationSeconds, ok
+}
+
+// LookupAwsSessionToken get aws_session_token, and reports whether it is set
+func (c *Config) LookupAwsSessionToken() (string, bool) {
+	_, ok := c.Lookup(AWS_SESSION_TOKEN)
+	return c.AwsSessionToken, ok
+}
+
+// LookupExternalID get external_id, and reports whether it is set
+func (c *Config) LookupExternalID() (string, bool) {
+	_, ok := c.Lookup(EXTERNAL_ID)
+	return c.ExternalID, ok
+}
+
+// LookupCaBundle get ca_bundle, and reports whether it is set
+func (c *Config) LookupCaBundle() (string, bool) {
+	_, ok := c.Lookup(CA_BUNDLE)
+	return c.CaBundle, ok
+}
+
+// LookupCliFollowUrlparam get cli_follow_urlparam, and reports whether it is set
+func (c *Config) LookupCliFollowUrlparam() (string, bool) {
+	_, ok := c.Lookup(CLI_FOLLOW_URLPARAM)
+	return c.CliFollowUrlparam, ok
+}
+
+// LookupCliTimestampFormat get cli_timestamp_format, and reports whether it is set
+func (c *Config) LookupCliTimestampFormat() (string, bool) {
+	_, ok := c.Lookup(CLI_TIMESTAMP_FORMAT)
+	return c.CliTimestampFormat, ok
+}
+
+// LookupCredentialProcess get credential_process, and reports whether it is set
+func (c *Config) LookupCredentialProcess() (string, bool) {
+	_, ok := c.Lookup(CREDENTIAL_PROCESS)
+	return c.CredentialProcess, ok
+}
+
+// LookupWebIdentityTokenFile get web_identity_token_file, and reports whether it is set
+func (c *Config) LookupWebIdentityTokenFile() (string, bool) {
+	_, ok := c.Lookup(WEB_IDENTITY_TOKEN_FILE)
+	return c.WebIdentityTokenFile, ok
+}
+
+// LookupOutput get output, and reports whether it is set
+func (c *Config) LookupOutput() (string, bool) {
+	_, ok := c.Lookup(OUTPUT)
+	return c.Output, ok
+}
+
+// LookupRegion get region, and reports whether it is set
+func (c *Config) LookupRegion() (string, bool) {
+	_, ok := c.Lookup(REGION)
+	return c.Region, ok
+}
+
+// LookupSSOStartURL get sso_start_url, and reports whether it is set
+func (c *Config) LookupSSOStartURL() (string, bool) {
+	_, ok := c.Lookup(SSO_START_URL)
+	return c.SSOStartURL, ok
+}
+
+// LookupSSORegion get sso_region, and reports whether it is set
+func (c *Config) LookupSSORegion() (string, bool) {
+	_, ok := c.Lookup(SSO_REGION)
+	return c.SSORegion, ok
+}
+
+// LookupSSOAccountID get sso_account_id, and reports whether it is set
+func (c *Config) LookupSSOAccountID() (string, bool) {
+	_, ok := c.Lookup(SSO_ACCOUNT_ID)
+	return c.SSOAccountID, ok
+}
+
+// LookupSSORoleName get sso_role_name, and reports whether it is set
+func (c *Config) LookupSSORoleName() (string, bool) {
+	_, ok := c.Lookup(SSO_ROLE_NAME)
+	return c.SSORoleName, ok
+}
+
+// LookupSSOSession get sso_session, and reports whether it is set
+func (c *Config) LookupSSOSession() (string, bool) {
+	_, ok := c.Lookup(SSO_SESSION)
+	return c.SSOSession, ok
+}
+
+// LookupServices get services, and reports whether it is set
+func (c *Config) LookupServices() (string, bool) {
+	_, ok := c.Lookup(SERVICES)
+	return c.Services, ok
+}
+
+// LookupEndpointURL get endpoint_url, and reports whether it is set
+func (c *Config) LookupEndpointURL() (string, bool) {
+	_, ok := c.Lookup(ENDPOINT_URL)
+	return c.EndpointURL, ok
+}
+
+// LookupIgnoreConfiguredEndpointURLs get ignore_configured_endpoint_urls, and reports whether it is set
+func (c *Config) LookupIgnoreConfiguredEndpointURLs() (bool, bool) {
+	_, ok := c.Lookup(IGNORE_CONFIGURED_ENDPOINT_URLS)
+	return c.IgnoreConfiguredEndpointURLs, ok
+}
+
+// LookupRetryMode get retry_mode, and reports whether it is set
+func (c *Config) LookupRetryMode() (string, bool) {
+	_, ok := c.Lookup(RETRY_MODE)
+	return c.RetryMode, ok
+}
+
+// LookupMaxAttempts get max_attempts, and reports whether it is set
+func (c *Config) LookupMaxAttempts() (int, bool) {
+	_, ok := c.Lookup(MAX_ATTEMPTS)
+	return c.MaxAttempts, ok
+}
+
+// LookupDefaultsMode get defaults_mode, and reports whether it is set
+func (c *Config) LookupDefaultsMode() (string, bool) {
+	_, ok := c.Lookup(DEFAULTS_MODE)
+	return c.DefaultsMode, ok
+}
+
+// LookupUseFIPSEndpoint get use_fips_endpoint, and reports whether it is set
+func (c *Config) LookupUseFIPSEndpoint() (bool, bool) {
+	_, ok := c.Lookup(USE_FIPS_ENDPOINT)
+	return c.UseFIPSEndpoint, ok
+}
+
+// LookupUseDualstackEndpoint get use_dualstack_endpoint, and reports whether it is set
+func (c *Config) LookupUseDualstackEndpoint() (bool, bool) {
+	_, ok := c.Lookup(USE_DUALSTACK_ENDPOINT)
+	return c.UseDualstackEndpoint, ok
+}
+
+// LookupSTSRegionalEndpoints get sts_regional_endpoints, and reports whether it is set
+func (c *Config) LookupSTSRegionalEndpoints() (string, bool) {
+	_, ok := c.Lookup(STS_REGIONAL_ENDPOINTS)
+	return c.STSRegionalEndpoints, ok
+}
+
+// LookupEC2MetadataServiceEndpoint get ec2_metadata_service_endpoint, and reports whether it is set
+func (c *Config) LookupEC2MetadataServiceEndpoint() (string, bool) {
+	_, ok := c.Lookup(EC2_METADATA_SERVICE_ENDPOINT)
+	return c.EC2MetadataServiceEndpoint, ok
+}
+
+// LookupEC2MetadataServiceEndpointMode get ec2_metadata_service_endpoint_mode, and reports whether it is set
+func (c *Config) LookupEC2MetadataServiceEndpointMode() (string, bool) {
+	_, ok := c.Lookup(EC2_METADATA_SERVICE_ENDPOINT_MODE)
+	return c.EC2MetadataServiceEndpointMode, ok
+}
+
+// LookupMetadataServiceTimeout get metadata_service_timeout, and reports whether it is set
+func (c *Config) LookupMetadataServiceTimeout() (int, bool) {
+	_, ok := c.Lookup(METADATA_SERVICE_TIMEOUT)
+	return c.MetadataServiceTimeout, ok
+}
+
+// LookupMetadataServiceNumAttempts get metadata_service_num_attempts, and reports whether it is set
+func (c *Config) LookupMetadataServiceNumAttempts() (int, bool) {
+	_, ok := c.Lookup(METADATA_SERVICE_NUM_ATTEMPTS)
+	return c.MetadataServiceNumAttempts, ok
+}
+
+// LookupCliPager get cli_pager, and reports whether it is set
+func (c *Config) LookupCliPager() (string, bool) {
+	_, ok := c.Lookup(CLI_PAGER)
+	return c.CliPager, ok
+}
+
+// LookupCliAutoPrompt get cli_auto_prompt, and reports whether it is set
+func (c *Config) LookupCliAutoPrompt() (string, bool) {
+	_, ok := c.Lookup(CLI_AUTO_PROMPT)
+	return c.CliAutoPrompt, ok
+}
+
+// LookupParameterValidation get parameter_validation, and reports whether it is set
+func (c *Config) LookupParameterValidation() (bool, bool) {
+	_, ok := c.Lookup(PARAMETER_VALIDATION)
+	return c.ParameterValidation, ok
+}
+
+// LookupTCPKeepalive get tcp_keepalive, and reports whether it is set
+func (c *Config) LookupTCPKeepalive() (bool, bool) {
+	_, ok := c.Lookup(TCP_KEEPALIVE)
+	return c.TCPKeepalive, ok
+}
+
+// LookupSourceIdentity get source_identity, and reports whether it is set
+func (c *Config) LookupSourceIdentity() (string, bool) {
+	_, ok := c.Lookup(SOURCE_IDENTITY)
+	return c.SourceIdentity, ok
+}
+
+// Lookup get a value of any key as it is written, and reports whether the key is set.
+// A key is set when it is in the file, even if the value is empty, or when the field has a non-zero value.
+func (c *Credential) Lookup(key string) (string, bool) {
+	return lookupKeyValue(c.keyValues(), key)
+}
+
+// Unset remove a key, so that Save removes the key from the file
+func (c *Credential) Unset(key string) {
+	c.Extra = withoutString(c.Extra, key)
+	c.Config.Unset(key)
+
+	switch key {
+	case AwsAccessKeyID:
+		c.AwsAccessKeyID = EmptyString
+	case AwsSecretAccessKey:
+		c.AwsSecretAccessKey = EmptyString
+	case AWS_SESSION_TOKEN:
+		c.AwsSessionToken = EmptyString
+	case AwsExpiration, XSecurityTokenExpires:
+		if key == c.expirationKeyName() {
+			c.Expiration = time.Time{}
+			c.expirationKey, c.expirationValue = EmptyString, EmptyString
+		}
+	}
+}
+
+// LookupAwsAccessKeyID get aws_access_key_id, and reports whether it is set
+func (c *Credential) LookupAwsAccessKeyID() (string, bool) {
+	_, ok := c.Lookup(AwsAccessKeyID)
+	return c.AwsAccessKeyID, ok
+}
+
+// LookupAwsSecretAccessKey get aws_secret_access_key, and reports whether it is set
+func (c *Credential) LookupAwsSecretAccessKey() (string, bool) {
+	_, ok := c.Lookup(AwsSecretAccessKey)
+	return c.AwsSecretAccessKey, ok
+}
+
+// LookupAwsSessionToken get aws_session_token, and reports whether it is set
+func (c *Credential) LookupAwsSessionToken() (string, bool) {
+	_, ok := c.Lookup(AWS_SESSION_TOKEN)
+	return c.AwsSessionToken, ok
+}
+
+// LookupExpiration get aws_expiration or x_security_token_expires, and reports whether it is set
+func (c *Credential) LookupExpiration() (time.Time, bool) {
+	return c.Expiration, !c.Expiration.IsZero()
+}
+
+// withoutNested returns m without nested keys of parent
+func withoutNested(m map[string]bool, parent string) map[string]bool {
+	for key := range m {
+		if strings.HasPrefix(key, parent+".") {
+			m = withoutKey(m, key)
+		}
+	}
+
+	return m
+}
+
+// withoutKey returns a copy of m without key.
+// m is not modified, because copies of a Config share it and Config.Set may not be called.
+func withoutKey(m map[string]bool, key string) map[string]bool {
+	if !m[key] {
+		return m
+	}
+
+	copied := make(map[string]bool)
+	for k, v := range m {
+		if k != key {
+			copied[k] = v
+		}
+	}
+
+	return copied
+}
+
+// withoutString returns a copy of m without key, like withoutKey
+func withoutString(m map[string]string, key string) map[string]string {
+	if _, ok := m[key]; !ok {
+		return m
+	}
+
+	copied := make(map[string]string)
+	for k, v := range m {
+		if k != key {
+			copied[k] = v
+		}
+	}
+
+	return copied
+}
+
+// withoutNestedValues returns a copy of m without key of parent, or without parent when key is empty, like withoutKey
+func withoutNestedValues(m map[string]map[string]string, parent, key string) map[string]map[string]string {
+	values, ok := m[parent]
+	if !ok {
+		return m
+	}
+
+	if _, ok := values[key]; key != EmptyString && !ok {
+		return m
+	}
+
+	copied := make(map[string]map[string]string)
+	for k, v := range m {
+		copied[k] = v
+	}
+
+	if key == EmptyString {
+		delete(copied, parent)
+	} else {
+		copied[parent] = withoutString(values, key)
+	}
+
+	return copied
+}
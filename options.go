@@ -0,0 +1,153 @@
+package awsprofile
+
+import (
+	"io/fs"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	homedir "github.com/mitchellh/go-homedir"
+)
+
+// Option configure how New finds and reads credentials file and config file
+type Option func(*options)
+
+// options are set by Option. The zero value uses the environment variables and files of the process.
+type options struct {
+	credentialsFile string
+	configFile      string
+	homeDir         string
+	fsys            fs.FS
+	env             map[string]string
+	interval        time.Duration
+}
+
+// WithCredentialsFile use credentialsFile instead of AWS_SHARED_CREDENTIALS_FILE and ~/.aws/credentials
+func WithCredentialsFile(credentialsFile string) Option {
+	return func(o *options) {
+		o.credentialsFile = credentialsFile
+	}
+}
+
+// WithConfigFile use configFile instead of AWS_CONFIG_FILE and ~/.aws/config
+func WithConfigFile(configFile string) Option {
+	return func(o *options) {
+		o.configFile = configFile
+	}
+}
+
+// WithHomeDir expand ~ of file paths to homeDir, e.g. to parse the files of another user
+func WithHomeDir(homeDir string) Option {
+	return func(o *options) {
+		o.homeDir = homeDir
+	}
+}
+
+// WithFS read files from fsys instead of the operating system, e.g. embed.FS or fstest.MapFS.
+// Paths are relative to the root of fsys, and ~ is the root unless WithHomeDir is given.
+func WithFS(fsys fs.FS) Option {
+	return func(o *options) {
+		o.fsys = fsys
+	}
+}
+
+// WithEnv look up AWS_SHARED_CREDENTIALS_FILE, AWS_CONFIG_FILE and HOME in env instead of the environment variables
+func WithEnv(env map[string]string) Option {
+	return func(o *options) {
+		o.env = env
+	}
+}
+
+// WithWatchInterval check the files every interval in Watch instead of DefaultWatchInterval
+func WithWatchInterval(interval time.Duration) Option {
+	return func(o *options) {
+		o.interval = interval
+	}
+}
+
+func (o options) watchInterval() time.Duration {
+	if o.interval > 0 {
+		return o.interval
+	}
+
+	return DefaultWatchInterval
+}
+
+func (o options) getenv(key string) string {
+	if o.env != nil {
+		return o.env[key]
+	}
+
+	return os.Getenv(key)
+}
+
+// credentialsPath is WithCredentialsFile, AWS_SHARED_CREDENTIALS_FILE or ~/.aws/credentials
+func (o options) credentialsPath() (string, error) {
+	return o.path(o.credentialsFile, AwsSharedCredentialsFile, AwsCredentials)
+}
+
+// configPath is WithConfigFile, AWS_CONFIG_FILE or ~/.aws/config
+func (o options) configPath() (string, error) {
+	return o.path(o.configFile, AWS_CONFIG_FILE, AWS_CONFIG)
+}
+
+func (o options) path(file, key, defaultFile string) (string, error) {
+	if file == EmptyString {
+		file = o.getenv(key)
+	}
+
+	if file == EmptyString {
+		file = defaultFile
+	}
+
+	return o.expand(file)
+}
+
+// home is the directory of ~, which is empty when ~ is expanded by homedir
+func (o options) home() string {
+	switch {
+	case o.homeDir != EmptyString:
+		return o.homeDir
+	case o.env != nil && o.env["HOME"] != EmptyString:
+		return o.env["HOME"]
+	case o.fsys != nil:
+		return "."
+	}
+
+	return EmptyString
+}
+
+func (o options) expand(file string) (string, error) {
+	home := o.home()
+
+	// ~user is left to homedir, which returns an error
+	if home == EmptyString || !strings.HasPrefix(file, "~") || (len(file) > 1 && file[1] != '/' && file[1] != '\\') {
+		return homedir.Expand(file)
+	}
+
+	return filepath.Join(home, file[1:]), nil
+}
+
+// fsPath convert file to a path of fsys, which is slash-separated and unrooted
+func fsPath(file string) string {
+	return strings.TrimPrefix(path.Clean(filepath.ToSlash(file)), "/")
+}
+
+func (o options) readFile(file string) ([]byte, error) {
+	if o.fsys != nil {
+		return fs.ReadFile(o.fsys, fsPath(file))
+	}
+
+	return ioutil.ReadFile(file)
+}
+
+func (o options) stat(file string) (fs.FileInfo, error) {
+	if o.fsys != nil {
+		return fs.Stat(o.fsys, fsPath(file))
+	}
+
+	return os.Stat(file)
+}
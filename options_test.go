@@ -0,0 +1,124 @@
+package awsprofile_test
+
+import (
+	"fmt"
+	"log"
+	"testing"
+	"testing/fstest"
+
+	"github.com/youyo/awsprofile"
+)
+
+func ExampleWithFS() {
+	fsys := fstest.MapFS{
+		".aws/credentials": {Data: []byte("[default]\naws_access_key_id = AKIA\naws_secret_access_key = secret\n")},
+		".aws/config":      {Data: []byte("[default]\nregion = us-east-1\n")},
+	}
+
+	awsProfile := awsprofile.New(awsprofile.WithFS(fsys), awsprofile.WithEnv(map[string]string{}))
+	if err := awsProfile.Parse(); err != nil {
+		log.Fatal(err)
+	}
+
+	region, err := awsProfile.GetConfigs().GetRegion("default")
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	fmt.Println(region)
+	// Output: us-east-1
+}
+
+func TestNew_WithFile(t *testing.T) {
+	awsProfile := awsprofile.New(
+		awsprofile.WithCredentialsFile("./tests/.aws/credentials"),
+		awsprofile.WithConfigFile("./tests/chain/.aws/config"),
+		awsprofile.WithEnv(map[string]string{
+			"AWS_SHARED_CREDENTIALS_FILE": "./nothing/credentials",
+			"AWS_CONFIG_FILE":             "./nothing/config",
+		}),
+	)
+	if err := awsProfile.Parse(); err != nil {
+		t.Fatal(err)
+	}
+
+	if awsProfile.Credentials.Len() != 3 {
+		t.Error("Unexpected credentials", awsProfile.Credentials.Len())
+	}
+
+	if ok, _ := awsProfile.IsConfig("admin"); !ok {
+		t.Error("config file is not parsed")
+	}
+}
+
+func TestNew_WithEnv(t *testing.T) {
+	awsProfile := awsprofile.New(awsprofile.WithEnv(map[string]string{
+		"AWS_SHARED_CREDENTIALS_FILE": "./tests/.aws/credentials",
+		"AWS_CONFIG_FILE":             "./tests/.aws/config",
+	}))
+	if err := awsProfile.Parse(); err != nil {
+		t.Fatal(err)
+	}
+
+	if profiles, _ := awsProfile.ProfileNames(); fmt.Sprint(profiles) != "[default foo foobar bar barbar]" {
+		t.Error("Unexpected profiles", profiles)
+	}
+}
+
+func TestNew_WithHomeDir(t *testing.T) {
+	awsProfile := awsprofile.New(
+		awsprofile.WithHomeDir("./tests/sso"),
+		awsprofile.WithEnv(map[string]string{}),
+	)
+	if err := awsProfile.Parse(); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := awsProfile.GetSSOSession("sso-dev"); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestNew_WithFS(t *testing.T) {
+	noEnv := awsprofile.WithEnv(map[string]string{})
+	fsys := fstest.MapFS{
+		"home/foo/.aws/credentials": {Data: []byte("[foo]\naws_access_key_id = AKIA\n")},
+		"home/foo/.aws/config":      {Data: []byte("[profile foo]\nregion = us-west-2\n")},
+		"etc/config":                {Data: []byte("[profile bar]\nregion = eu-west-1\n")},
+	}
+
+	tests := []struct {
+		opts   []awsprofile.Option
+		expect string
+	}{
+		{[]awsprofile.Option{awsprofile.WithHomeDir("/home/foo")}, "[foo]"},
+		{[]awsprofile.Option{awsprofile.WithHomeDir("/home/foo"), awsprofile.WithConfigFile("/etc/config")}, "[foo bar]"},
+	}
+
+	for _, tt := range tests {
+		awsProfile := awsprofile.New(append(tt.opts, awsprofile.WithFS(fsys), noEnv)...)
+		if err := awsProfile.Parse(); err != nil {
+			t.Fatal(err)
+		}
+
+		if profiles, _ := awsProfile.ProfileNames(); fmt.Sprint(profiles) != tt.expect {
+			t.Error("Unexpected profiles", profiles, "expect", tt.expect)
+		}
+	}
+
+	awsProfile := awsprofile.New(awsprofile.WithFS(fsys), awsprofile.WithEnv(map[string]string{"HOME": "home/foo"}))
+	if err := awsProfile.Parse(); err != nil {
+		t.Fatal(err)
+	} else if ok, _ := awsProfile.IsCredential("foo"); !ok {
+		t.Error("HOME of env is not used")
+	}
+
+	awsProfile = awsprofile.New(awsprofile.WithFS(fsys), noEnv)
+	if err := awsProfile.Parse(); err != nil {
+		t.Fatal(err)
+	}
+
+	if file, ok := awsProfile.ConfigFile(); ok || file != ".aws/config" {
+		t.Error("Unexpected config file", file, ok)
+	}
+}
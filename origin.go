@@ -0,0 +1,74 @@
+package awsprofile
+
+import "strconv"
+
+// Origin is where a key is written: the path of the file, the line number and the section, e.g. profile dev.
+// Path is the source name for ParseBytes and ParseReader.
+type Origin struct {
+	Path    string
+	Line    int
+	Section string
+}
+
+func (o Origin) String() string {
+	location := o.Path
+	if o.Line != ZeroInt {
+		location += ":" + strconv.Itoa(o.Line)
+	}
+
+	if o.Section != EmptyString {
+		location += " [" + o.Section + "]"
+	}
+
+	return location
+}
+
+// origins are Origin of keys. Nested keys are joined with a dot, e.g. s3.max_concurrent_requests.
+type origins map[string]Origin
+
+func (o *origins) set(key string, origin Origin) {
+	if *o == nil {
+		*o = make(origins)
+	}
+
+	(*o)[key] = origin
+}
+
+// setFrom set Origin of key in from, or delete Origin of key when from does not have it
+func (o *origins) setFrom(from origins, key string) {
+	if origin, ok := from.get(key); ok {
+		o.set(key, origin)
+		return
+	}
+
+	delete(*o, key)
+}
+
+func (o origins) copy() origins {
+	var copied origins
+	for key, origin := range o {
+		copied.set(key, origin)
+	}
+
+	return copied
+}
+
+func (o origins) get(key string) (Origin, bool) {
+	origin, ok := o[key]
+	return origin, ok
+}
+
+// Origin get where a key of the profile is written. ok is false for keys which are not parsed from a file.
+func (c *Config) Origin(key string) (Origin, bool) {
+	return c.origins.get(key)
+}
+
+// Origin get where a key of the profile is written. ok is false for keys which are not parsed from a file.
+func (c *Credential) Origin(key string) (Origin, bool) {
+	return c.origins.get(key)
+}
+
+// Origin get where a key of the sso-session is written. ok is false for keys which are not parsed from a file.
+func (s *SSOSession) Origin(key string) (Origin, bool) {
+	return s.origins.get(key)
+}
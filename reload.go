@@ -0,0 +1,144 @@
+package awsprofile
+
+import (
+	"errors"
+	"io/fs"
+	"time"
+)
+
+// fileState is the path, modification time and size of a parsed file.
+// found is false when the file does not exist, and it is parsed as an empty file.
+type fileState struct {
+	path    string
+	found   bool
+	modTime time.Time
+	size    int64
+}
+
+func (o options) statFile(path string) (fileState, error) {
+	info, err := o.stat(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return fileState{path: path}, nil
+	}
+	if err != nil {
+		return fileState{}, err
+	}
+
+	return fileState{path: path, found: true, modTime: info.ModTime(), size: info.Size()}, nil
+}
+
+// readState returns nil for a file which does not exist
+func (o options) readState(state fileState) ([]byte, error) {
+	if !state.found {
+		return nil, nil
+	}
+
+	return o.readFile(state.path)
+}
+
+func (f fileState) equal(state fileState) bool {
+	return f.path == state.path && f.found == state.found && f.modTime.Equal(state.modTime) && f.size == state.size
+}
+
+// CredentialsFile get the path of credentials file which is parsed, and whether the file is found.
+// Parse and Reload treat a credentials file which does not exist as an empty file.
+func (a *AwsProfile) CredentialsFile() (string, bool) {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	return a.credentialsState.path, a.credentialsState.found
+}
+
+// ConfigFile get the path of config file which is parsed, and whether the file is found.
+// Parse and Reload treat a config file which does not exist as an empty file.
+func (a *AwsProfile) ConfigFile() (string, bool) {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	return a.configState.path, a.configState.found
+}
+
+// Reload parse credential file and config file again like Parse or ParseLenient which was called before,
+// but only files whose path, modification time or size is changed are parsed.
+// It reports whether any file is parsed.
+func (a *AwsProfile) Reload() (bool, error) {
+	a.reloadMu.Lock()
+	defer a.reloadMu.Unlock()
+
+	return a.reload()
+}
+
+// reload is Reload which is called with reloadMu locked.
+// Files are parsed without mu locked, and the results are swapped in with mu locked.
+func (a *AwsProfile) reload() (bool, error) {
+	var errs ParseErrors
+
+	current := a.Snapshot()
+
+	credentialsFile, err := current.options.credentialsPath()
+	if err != nil {
+		return false, err
+	}
+
+	credentialsState, err := current.options.statFile(credentialsFile)
+	if err != nil {
+		return false, err
+	}
+
+	credentials := current.Credentials
+
+	if !credentialsState.equal(current.credentialsState) {
+		credentials = NewCredentials()
+
+		data, err := current.options.readState(credentialsState)
+		if err != nil {
+			return false, err
+		}
+
+		if errs, err = appendParseErrors(errs, credentials.parse(data, credentialsFile, current.lenient)); err != nil {
+			return false, err
+		}
+	}
+
+	configsFile, err := current.options.configPath()
+	if err != nil {
+		return false, err
+	}
+
+	configState, err := current.options.statFile(configsFile)
+	if err != nil {
+		return false, err
+	}
+
+	configs, ssoSessions, services := current.Configs, current.SSOSessions, current.Services
+
+	if !configState.equal(current.configState) {
+		configs, ssoSessions, services = NewConfigs(), NewSSOSessions(), NewServices()
+
+		data, err := current.options.readState(configState)
+		if err != nil {
+			return false, err
+		}
+
+		if errs, err = appendParseErrors(errs, configs.parse(data, configsFile, current.lenient)); err != nil {
+			return false, err
+		}
+
+		if err = ssoSessions.parse(data, configsFile, current.lenient); err != nil {
+			return false, err
+		}
+
+		if err = services.parse(data, configsFile, current.lenient); err != nil {
+			return false, err
+		}
+	}
+
+	reloaded := !credentialsState.equal(current.credentialsState) || !configState.equal(current.configState)
+
+	a.mu.Lock()
+	a.Credentials, a.Configs, a.SSOSessions, a.Services = credentials, configs, ssoSessions, services
+	a.credentialsState, a.configState = credentialsState, configState
+	a.mu.Unlock()
+
+	return reloaded, errs.err()
+}
@@ -0,0 +1,280 @@
+package awsprofile_test
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/youyo/awsprofile"
+)
+
+func TestAwsProfile_Parse_Twice(t *testing.T) {
+	os.Setenv("AWS_SHARED_CREDENTIALS_FILE", "./tests/.aws/credentials")
+	os.Setenv("AWS_CONFIG_FILE", "./tests/.aws/config")
+
+	awsProfile := awsprofile.New()
+	for i := 0; i < 2; i++ {
+		if err := awsProfile.Parse(); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if awsProfile.Credentials.Len() != 3 || awsProfile.Configs.Len() != 3 {
+		t.Fatal("profiles are duplicated", awsProfile.Credentials.Len(), awsProfile.Configs.Len())
+	}
+}
+
+func TestConfigs_Parse_Replace(t *testing.T) {
+	configs := awsprofile.NewConfigs()
+	configs.Set(awsprofile.Config{ProfileName: "stale"})
+
+	if err := configs.Parse("./tests/.aws/config"); err != nil {
+		t.Fatal(err)
+	}
+
+	if profiles, _ := configs.ProfileNames(); fmt.Sprint(profiles) != "[default bar barbar]" {
+		t.Fatal("Unexpected profiles", profiles)
+	}
+
+	// configs are not changed when Parse fails
+	if err := configs.Parse("./tests/invalid/.aws/config"); err == nil {
+		t.Fatal("Parse should fail")
+	}
+
+	if configs.Len() != 3 {
+		t.Fatal("configs are changed", configs.Len())
+	}
+}
+
+func TestAwsProfile_Reload(t *testing.T) {
+	dir := tempDir(t)
+	defer os.RemoveAll(dir)
+
+	credentialsFile := filepath.Join(dir, "credentials")
+	configFile := filepath.Join(dir, "config")
+	copyFile(t, "./tests/.aws/credentials", credentialsFile)
+	copyFile(t, "./tests/.aws/config", configFile)
+
+	os.Setenv("AWS_SHARED_CREDENTIALS_FILE", credentialsFile)
+	os.Setenv("AWS_CONFIG_FILE", configFile)
+
+	awsProfile := awsprofile.New()
+	if err := awsProfile.Parse(); err != nil {
+		t.Fatal(err)
+	}
+
+	if reloaded, err := awsProfile.Reload(); err != nil {
+		t.Fatal(err)
+	} else if reloaded {
+		t.Fatal("files are not changed")
+	}
+
+	// a change of Credentials is kept, because credentials file is not parsed again
+	awsProfile.Credentials.Delete("foobar")
+
+	data, err := ioutil.ReadFile(configFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	data = append(data, "\n[profile added]\nregion = eu-west-1\n"...)
+	if err = ioutil.WriteFile(configFile, data, awsprofile.FileMode); err != nil {
+		t.Fatal(err)
+	}
+
+	if reloaded, err := awsProfile.Reload(); err != nil {
+		t.Fatal(err)
+	} else if !reloaded {
+		t.Fatal("config file is changed")
+	}
+
+	if value, err := awsProfile.Configs.GetRegion("added"); err != nil || value != "eu-west-1" {
+		t.Fatal("Unmatched Region", value, err)
+	}
+
+	if awsProfile.Credentials.Len() != 2 {
+		t.Fatal("credentials file should not be parsed again", awsProfile.Credentials.Len())
+	}
+
+	// a file whose size is the same is parsed again when the modification time is changed
+	modTime := time.Now().Add(time.Hour)
+	if err = os.Chtimes(credentialsFile, modTime, modTime); err != nil {
+		t.Fatal(err)
+	}
+
+	if reloaded, err := awsProfile.Reload(); err != nil {
+		t.Fatal(err)
+	} else if !reloaded {
+		t.Fatal("credentials file is changed")
+	}
+
+	if awsProfile.Credentials.Len() != 3 {
+		t.Fatal("credentials file should be parsed again", awsProfile.Credentials.Len())
+	}
+}
+
+func TestAwsProfile_Reload_Concurrent(t *testing.T) {
+	dir := tempDir(t)
+	defer os.RemoveAll(dir)
+
+	credentialsFile := filepath.Join(dir, "credentials")
+	configFile := filepath.Join(dir, "config")
+	copyFile(t, "./tests/.aws/credentials", credentialsFile)
+
+	versions := []string{
+		"[profile a]\nregion = v1\n",
+		"[profile a]\nregion = v2\n\n[profile b]\nregion = v2\n",
+	}
+
+	if err := ioutil.WriteFile(configFile, []byte(versions[0]), awsprofile.FileMode); err != nil {
+		t.Fatal(err)
+	}
+
+	os.Setenv("AWS_SHARED_CREDENTIALS_FILE", credentialsFile)
+	os.Setenv("AWS_CONFIG_FILE", configFile)
+
+	awsProfile := awsprofile.New()
+	if err := awsProfile.Parse(); err != nil {
+		t.Fatal(err)
+	}
+
+	done := make(chan struct{})
+	errs := make(chan error, 4)
+
+	for i := 0; i < 4; i++ {
+		go func() {
+			for {
+				select {
+				case <-done:
+					errs <- nil
+					return
+				default:
+				}
+
+				snapshot := awsProfile.Snapshot()
+
+				region, err := snapshot.GetConfigs().GetRegion("a")
+				if err != nil {
+					errs <- err
+					return
+				}
+
+				b, _ := snapshot.IsConfig("b")
+				if (region == "v2") != b {
+					errs <- fmt.Errorf("inconsistent snapshot: region %s, profile b %v", region, b)
+					return
+				}
+
+				if _, err = awsProfile.Resolve("a"); err != nil {
+					errs <- err
+					return
+				}
+
+				if _, err = awsProfile.ProfileNames(); err != nil {
+					errs <- err
+					return
+				}
+			}
+		}()
+	}
+
+	for i := 0; i < 50; i++ {
+		if err := ioutil.WriteFile(configFile, []byte(versions[(i+1)%2]), awsprofile.FileMode); err != nil {
+			t.Fatal(err)
+		}
+
+		if _, err := awsProfile.Reload(); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	close(done)
+
+	for i := 0; i < 4; i++ {
+		if err := <-errs; err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestAwsProfile_Parse_MissingFile(t *testing.T) {
+	dir := tempDir(t)
+	defer os.RemoveAll(dir)
+
+	credentialsFile := filepath.Join(dir, "credentials")
+
+	awsProfile := awsprofile.New(awsprofile.WithEnv(map[string]string{
+		"AWS_SHARED_CREDENTIALS_FILE": credentialsFile,
+		"AWS_CONFIG_FILE":             "./tests/sso/.aws/config",
+	}))
+	if err := awsProfile.Parse(); err != nil {
+		t.Fatal(err)
+	}
+
+	if file, ok := awsProfile.CredentialsFile(); ok || file != credentialsFile {
+		t.Error("Unexpected credentials file", file, ok)
+	}
+
+	if file, ok := awsProfile.ConfigFile(); !ok || file != "./tests/sso/.aws/config" {
+		t.Error("Unexpected config file", file, ok)
+	}
+
+	if awsProfile.Credentials.Len() != 0 || awsProfile.Configs.Len() != 4 {
+		t.Fatal("Unexpected profiles", awsProfile.Credentials.Len(), awsProfile.Configs.Len())
+	}
+
+	// a created file is found by Reload, and a removed file is parsed as an empty file again
+	copyFile(t, "./tests/.aws/credentials", credentialsFile)
+
+	if reloaded, err := awsProfile.Reload(); err != nil || !reloaded {
+		t.Fatal("credentials file is created", reloaded, err)
+	}
+
+	if _, ok := awsProfile.CredentialsFile(); !ok || awsProfile.Credentials.Len() != 3 {
+		t.Fatal("credentials file should be found", awsProfile.Credentials.Len())
+	}
+
+	if err := os.Remove(credentialsFile); err != nil {
+		t.Fatal(err)
+	}
+
+	if reloaded, err := awsProfile.Reload(); err != nil || !reloaded {
+		t.Fatal("credentials file is removed", reloaded, err)
+	}
+
+	if _, ok := awsProfile.CredentialsFile(); ok || awsProfile.Credentials.Len() != 0 {
+		t.Fatal("credentials file should not be found", awsProfile.Credentials.Len())
+	}
+}
+
+// deniedFS is a fs.FS whose files are all not permitted
+type deniedFS struct{}
+
+func (deniedFS) Open(name string) (fs.File, error) {
+	return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrPermission}
+}
+
+func TestAwsProfile_Parse_PermissionError(t *testing.T) {
+	awsProfile := awsprofile.New(awsprofile.WithFS(deniedFS{}), awsprofile.WithEnv(map[string]string{}))
+
+	if err := awsProfile.Parse(); !errors.Is(err, fs.ErrPermission) {
+		t.Fatal("Unexpected error", err)
+	}
+}
+
+func TestAwsProfile_Parse_MissingFile_ParseError(t *testing.T) {
+	awsProfile := awsprofile.New(awsprofile.WithEnv(map[string]string{
+		"AWS_SHARED_CREDENTIALS_FILE": "./tests/nothing/credentials",
+		"AWS_CONFIG_FILE":             "./tests/invalid/.aws/config",
+	}))
+
+	var parseError *awsprofile.ParseError
+	if err := awsProfile.Parse(); !errors.As(err, &parseError) {
+		t.Fatal("Unexpected error", err)
+	}
+}
@@ -0,0 +1,114 @@
+package awsprofile
+
+// ResolvedProfile provide the effective settings of a profile.
+// Config holds the keys of the config file, overridden by the keys of the credentials file.
+// Session is the sso-session which the profile refers by sso_session,
+// or nil when sso_session is not set or the sso-session section is not found.
+type ResolvedProfile struct {
+	Config
+	Session       *SSOSession
+	InCredentials bool
+	InConfig      bool
+}
+
+// Resolve merge a profile of the credentials file and the config file.
+// Like the AWS CLI, values in the credentials file take precedence over values in the config file,
+// not only credentials but also keys such as region and role_arn.
+func (a *AwsProfile) Resolve(profile string) (*ResolvedProfile, error) {
+	snapshot := a.Snapshot()
+
+	okCredential, credential := snapshot.IsCredential(profile)
+	okConfig, config := snapshot.IsConfig(profile)
+
+	if !okCredential && !okConfig {
+		return nil, snapshot.notFound(profile)
+	}
+
+	resolved := &ResolvedProfile{
+		InCredentials: okCredential,
+		InConfig:      okConfig,
+	}
+
+	if okConfig {
+		resolved.Config = *config
+	}
+
+	resolved.ProfileName = profile
+
+	if okCredential {
+		resolved.Config = resolved.Config.merge(credential)
+	}
+
+	if resolved.SSOSession != EmptyString {
+		// a dangling sso_session is reported by GetSSOSession, and the rest of the profile is still resolved
+		if session, ok := snapshot.SSOSessions.get(resolved.SSOSession); ok {
+			resolved.Session = session
+
+			if resolved.SSOStartURL == EmptyString {
+				resolved.SSOStartURL = session.SSOStartURL
+			}
+
+			if resolved.SSORegion == EmptyString {
+				resolved.SSORegion = session.SSORegion
+			}
+		}
+	}
+
+	return resolved, nil
+}
+
+// merge returns a copy of c overridden by the keys of credential which have a value.
+// Maps are copied, so c and the configs sharing them are not changed.
+func (c Config) merge(credential *Credential) Config {
+	present := make(map[string]bool)
+	for key, value := range c.present {
+		present[key] = value
+	}
+	c.present = present
+
+	c.written = copyStrings(c.written)
+	c.Extra = copyStrings(c.Extra)
+
+	if c.Nested != nil {
+		nested := make(map[string]map[string]string)
+		for key, values := range c.Nested {
+			nested[key] = copyStrings(values)
+		}
+		c.Nested = nested
+	}
+
+	c.origins = c.origins.copy()
+
+	for _, kv := range credential.keyValues() {
+		if kv.nested == nil {
+			if kv.value == EmptyString {
+				continue
+			}
+
+			// values of credential are already parsed, so there is no error
+			_ = c.setValue(kv.key, kv.value)
+			c.origins.setFrom(credential.origins, kv.key)
+			continue
+		}
+
+		for _, nested := range kv.nested {
+			_ = c.setNested(kv.key, nested)
+			c.origins.setFrom(credential.origins, kv.key+"."+nested.key)
+		}
+	}
+
+	return c
+}
+
+func copyStrings(m map[string]string) map[string]string {
+	if m == nil {
+		return nil
+	}
+
+	copied := make(map[string]string)
+	for key, value := range m {
+		copied[key] = value
+	}
+
+	return copied
+}
@@ -0,0 +1,174 @@
+package awsprofile_test
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"testing"
+	"testing/fstest"
+
+	"github.com/youyo/awsprofile"
+)
+
+func ExampleAwsProfile_Resolve() {
+	// if you use non-default configuration file path
+	if err := os.Setenv("AWS_SHARED_CREDENTIALS_FILE", "./tests/.aws/credentials"); err != nil {
+		log.Fatal(err)
+	}
+	if err := os.Setenv("AWS_CONFIG_FILE", "./tests/.aws/config"); err != nil {
+		log.Fatal(err)
+	}
+
+	awsProfile := awsprofile.New()
+	if err := awsProfile.Parse(); err != nil {
+		log.Fatal(err)
+	}
+
+	// Merge credentials file and config file
+	profile, err := awsProfile.Resolve("default")
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	fmt.Println(profile.GetAwsAccessKeyID(), profile.GetRegion())
+	// Output: ACCESS-1-XXXXXXXXXXXXX ap-northeast-1
+}
+
+func TestAwsProfile_Resolve(t *testing.T) {
+	awsProfile := newProfile(t, "./tests/resolve")
+
+	tests := []struct {
+		profile            string
+		awsAccessKeyID     string
+		awsSecretAccessKey string
+		region             string
+		inCredentials      bool
+		inConfig           bool
+	}{
+		{"default", "ACCESS-DEFAULT-XXXXXXXX", "SECRET-DEFAULT-XXXXXXXX", "ap-northeast-1", true, true},
+		{"both", "ACCESS-CREDENTIALS-XXXX", "SECRET-CREDENTIALS-XXXX", "eu-west-1", true, true},
+		{"configonly", "ACCESS-CONFIG-ONLY-XXXX", "SECRET-CONFIG-ONLY-XXXX", "", false, true},
+		{"credentialsonly", "ACCESS-CREDENTIALS-ONLY", "SECRET-CREDENTIALS-ONLY", "", true, false},
+	}
+
+	for _, tt := range tests {
+		profile, err := awsProfile.Resolve(tt.profile)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if profile.ProfileName != tt.profile {
+			t.Error("profile", profile.ProfileName, "expect", tt.profile)
+		}
+		if profile.GetAwsAccessKeyID() != tt.awsAccessKeyID {
+			t.Error(tt.profile, "AwsAccessKeyID", profile.GetAwsAccessKeyID(), "expect", tt.awsAccessKeyID)
+		}
+		if profile.GetAwsSecretAccessKey() != tt.awsSecretAccessKey {
+			t.Error(tt.profile, "AwsSecretAccessKey", profile.GetAwsSecretAccessKey(), "expect", tt.awsSecretAccessKey)
+		}
+		if profile.GetRegion() != tt.region {
+			t.Error(tt.profile, "Region", profile.GetRegion(), "expect", tt.region)
+		}
+		if profile.InCredentials != tt.inCredentials || profile.InConfig != tt.inConfig {
+			t.Error(tt.profile, "InCredentials", profile.InCredentials, "InConfig", profile.InConfig)
+		}
+	}
+}
+
+func TestAwsProfile_Resolve_DefaultSection(t *testing.T) {
+	awsProfile := newProfile(t, "./tests/resolve")
+
+	profiles, _ := awsProfile.GetConfigs().ProfileNames()
+	if fmt.Sprint(profiles) != "[default both configonly]" {
+		t.Fatal("Unexpected profiles", profiles)
+	}
+
+	profile, err := awsProfile.Resolve("default")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if profile.GetOutput() != "json" {
+		t.Fatal("[profile default] should win over [default]", profile.GetOutput())
+	}
+}
+
+func TestAwsProfile_Resolve_NotFound(t *testing.T) {
+	awsProfile := newProfile(t, "./tests/resolve")
+
+	if _, err := awsProfile.Resolve("fooooo"); !errors.Is(err, awsprofile.ErrorNotFoundProfile) {
+		t.Fatal("Unexpected error", err)
+	}
+}
+
+func TestAwsProfile_Resolve_SessionToken(t *testing.T) {
+	awsProfile := awsprofile.New(
+		awsprofile.WithFS(fstest.MapFS{
+			".aws/credentials": {Data: []byte("[dev]\naws_session_token = credentials-token\n")},
+			".aws/config":      {Data: []byte("[profile dev]\naws_session_token = config-token\n")},
+		}),
+		awsprofile.WithEnv(map[string]string{}),
+	)
+	if err := awsProfile.Parse(); err != nil {
+		t.Fatal(err)
+	}
+
+	profile, err := awsProfile.Resolve("dev")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if profile.GetAwsSessionToken() != "credentials-token" {
+		t.Error("Unmatched AwsSessionToken", profile.GetAwsSessionToken())
+	}
+}
+
+func TestAwsProfile_Resolve_CredentialsConfig(t *testing.T) {
+	awsProfile := awsprofile.New(
+		awsprofile.WithCredentialsFile("./tests/mixed/.aws/credentials"),
+		awsprofile.WithConfigFile("./tests/mixed/.aws/config"),
+	)
+	if err := awsProfile.Parse(); err != nil {
+		t.Fatal(err)
+	}
+
+	profile, err := awsProfile.Resolve("dev")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if profile.GetRegion() != "eu-west-1" || profile.GetOutput() != "text" || profile.GetMaxAttempts() != 5 {
+		t.Error("credentials file should take precedence", profile.GetRegion(), profile.GetOutput(), profile.GetMaxAttempts())
+	}
+
+	if profile.S3.MaxConcurrentRequests != 20 || profile.S3.AddressingStyle != "path" {
+		t.Error("nested keys are not merged", profile.S3)
+	}
+
+	if origin, _ := profile.Origin("region"); origin.Path != "./tests/mixed/.aws/credentials" || origin.Line != 4 {
+		t.Error("Unmatched Origin", origin)
+	}
+
+	if origin, _ := profile.Origin("output"); origin.Path != "./tests/mixed/.aws/config" {
+		t.Error("Unmatched Origin", origin)
+	}
+
+	// the config file is not changed by Resolve
+	if value, _ := awsProfile.GetConfigs().GetRegion("dev"); value != "us-east-1" {
+		t.Error("config is changed", value)
+	}
+
+	if value, _ := awsProfile.GetConfigs().GetS3("dev"); value.MaxConcurrentRequests != 0 {
+		t.Error("config is changed", value)
+	}
+
+	chain, err := awsProfile.Chain("dev")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(chain) != 2 || chain[0].Kind != awsprofile.ChainAssumeRole || chain[1].ProfileName != "base" {
+		t.Error("role_arn of credentials file is not followed", chain)
+	}
+}
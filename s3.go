@@ -0,0 +1,185 @@
+package awsprofile
+
+import (
+	"errors"
+	"strconv"
+)
+
+// nested keys of s3 and s3api
+const (
+	S3                        string = "s3"
+	S3API                     string = "s3api"
+	MAX_CONCURRENT_REQUESTS   string = "max_concurrent_requests"
+	MAX_QUEUE_SIZE            string = "max_queue_size"
+	MULTIPART_THRESHOLD       string = "multipart_threshold"
+	MULTIPART_CHUNKSIZE       string = "multipart_chunksize"
+	MAX_BANDWIDTH             string = "max_bandwidth"
+	USE_ACCELERATE_ENDPOINT   string = "use_accelerate_endpoint"
+	USE_DUALSTACK_ENDPOINT    string = "use_dualstack_endpoint"
+	ADDRESSING_STYLE          string = "addressing_style"
+	PAYLOAD_SIGNING_ENABLED   string = "payload_signing_enabled"
+	PREFERRED_TRANSFER_CLIENT string = "preferred_transfer_client"
+)
+
+// error messages
+var (
+	ErrorNotFoundS3    error = errors.New(S3 + ErrorNotFound)
+	ErrorNotFoundS3API error = errors.New(S3API + ErrorNotFound)
+)
+
+// S3Settings provide nested keys of s3 and s3api in a profile.
+// Sizes such as multipart_threshold are kept as written, e.g. 64MB.
+type S3Settings struct {
+	MaxConcurrentRequests   int
+	MaxQueueSize            int
+	MultipartThreshold      string
+	MultipartChunksize      string
+	MaxBandwidth            string
+	UseAccelerateEndpoint   bool
+	UseDualstackEndpoint    bool
+	AddressingStyle         string
+	PayloadSigningEnabled   bool
+	PreferredTransferClient string
+}
+
+// setValue set a nested value. It returns false for unknown keys.
+func (s *S3Settings) setValue(key, value string) (bool, error) {
+	var err error
+
+	switch key {
+	case MAX_CONCURRENT_REQUESTS:
+		s.MaxConcurrentRequests, err = strconv.Atoi(value)
+	case MAX_QUEUE_SIZE:
+		s.MaxQueueSize, err = strconv.Atoi(value)
+	case MULTIPART_THRESHOLD:
+		s.MultipartThreshold = value
+	case MULTIPART_CHUNKSIZE:
+		s.MultipartChunksize = value
+	case MAX_BANDWIDTH:
+		s.MaxBandwidth = value
+	case USE_ACCELERATE_ENDPOINT:
+		s.UseAccelerateEndpoint = parseBool(value)
+	case USE_DUALSTACK_ENDPOINT:
+		s.UseDualstackEndpoint = parseBool(value)
+	case ADDRESSING_STYLE:
+		s.AddressingStyle = value
+	case PAYLOAD_SIGNING_ENABLED:
+		s.PayloadSigningEnabled = parseBool(value)
+	case PREFERRED_TRANSFER_CLIENT:
+		s.PreferredTransferClient = value
+	default:
+		return false, nil
+	}
+
+	return true, err
+}
+
+// unset clear a nested value
+func (s *S3Settings) unset(key string) {
+	switch key {
+	case MAX_CONCURRENT_REQUESTS:
+		s.MaxConcurrentRequests = ZeroInt
+	case MAX_QUEUE_SIZE:
+		s.MaxQueueSize = ZeroInt
+	case MULTIPART_THRESHOLD:
+		s.MultipartThreshold = EmptyString
+	case MULTIPART_CHUNKSIZE:
+		s.MultipartChunksize = EmptyString
+	case MAX_BANDWIDTH:
+		s.MaxBandwidth = EmptyString
+	case USE_ACCELERATE_ENDPOINT:
+		s.UseAccelerateEndpoint = false
+	case USE_DUALSTACK_ENDPOINT:
+		s.UseDualstackEndpoint = false
+	case ADDRESSING_STYLE:
+		s.AddressingStyle = EmptyString
+	case PAYLOAD_SIGNING_ENABLED:
+		s.PayloadSigningEnabled = false
+	case PREFERRED_TRANSFER_CLIENT:
+		s.PreferredTransferClient = EmptyString
+	}
+}
+
+// keyValues returns nested values of parent, e.g. s3.
+// Zero values are kept when they are in the file, and unchanged values are written as they are,
+// which present and written have as parent.key.
+func (s *S3Settings) keyValues(parent string, present map[string]bool, written map[string]string) []keyValue {
+	var kvs []keyValue
+
+	isPresent := func(key string) bool {
+		return present[parent+"."+key]
+	}
+
+	if s.MaxConcurrentRequests != ZeroInt || isPresent(MAX_CONCURRENT_REQUESTS) {
+		kvs = appendKeyValue(kvs, MAX_CONCURRENT_REQUESTS, formatInt(written, parent+"."+MAX_CONCURRENT_REQUESTS, s.MaxConcurrentRequests))
+	}
+	if s.MaxQueueSize != ZeroInt || isPresent(MAX_QUEUE_SIZE) {
+		kvs = appendKeyValue(kvs, MAX_QUEUE_SIZE, formatInt(written, parent+"."+MAX_QUEUE_SIZE, s.MaxQueueSize))
+	}
+	kvs = appendKeyValue(kvs, MULTIPART_THRESHOLD, s.MultipartThreshold)
+	kvs = appendKeyValue(kvs, MULTIPART_CHUNKSIZE, s.MultipartChunksize)
+	kvs = appendKeyValue(kvs, MAX_BANDWIDTH, s.MaxBandwidth)
+	if s.UseAccelerateEndpoint || isPresent(USE_ACCELERATE_ENDPOINT) {
+		kvs = appendKeyValue(kvs, USE_ACCELERATE_ENDPOINT, formatBool(written, parent+"."+USE_ACCELERATE_ENDPOINT, s.UseAccelerateEndpoint))
+	}
+	if s.UseDualstackEndpoint || isPresent(USE_DUALSTACK_ENDPOINT) {
+		kvs = appendKeyValue(kvs, USE_DUALSTACK_ENDPOINT, formatBool(written, parent+"."+USE_DUALSTACK_ENDPOINT, s.UseDualstackEndpoint))
+	}
+	kvs = appendKeyValue(kvs, ADDRESSING_STYLE, s.AddressingStyle)
+	if s.PayloadSigningEnabled || isPresent(PAYLOAD_SIGNING_ENABLED) {
+		kvs = appendKeyValue(kvs, PAYLOAD_SIGNING_ENABLED, formatBool(written, parent+"."+PAYLOAD_SIGNING_ENABLED, s.PayloadSigningEnabled))
+	}
+	kvs = appendKeyValue(kvs, PREFERRED_TRANSFER_CLIENT, s.PreferredTransferClient)
+
+	return kvs
+}
+
+// GetMaxConcurrentRequests get max_concurrent_requests
+func (s S3Settings) GetMaxConcurrentRequests() int {
+	return s.MaxConcurrentRequests
+}
+
+// GetMaxQueueSize get max_queue_size
+func (s S3Settings) GetMaxQueueSize() int {
+	return s.MaxQueueSize
+}
+
+// GetMultipartThreshold get multipart_threshold
+func (s S3Settings) GetMultipartThreshold() string {
+	return s.MultipartThreshold
+}
+
+// GetMultipartChunksize get multipart_chunksize
+func (s S3Settings) GetMultipartChunksize() string {
+	return s.MultipartChunksize
+}
+
+// GetMaxBandwidth get max_bandwidth
+func (s S3Settings) GetMaxBandwidth() string {
+	return s.MaxBandwidth
+}
+
+// GetUseAccelerateEndpoint get use_accelerate_endpoint
+func (s S3Settings) GetUseAccelerateEndpoint() bool {
+	return s.UseAccelerateEndpoint
+}
+
+// GetUseDualstackEndpoint get use_dualstack_endpoint
+func (s S3Settings) GetUseDualstackEndpoint() bool {
+	return s.UseDualstackEndpoint
+}
+
+// GetAddressingStyle get addressing_style
+func (s S3Settings) GetAddressingStyle() string {
+	return s.AddressingStyle
+}
+
+// GetPayloadSigningEnabled get payload_signing_enabled
+func (s S3Settings) GetPayloadSigningEnabled() bool {
+	return s.PayloadSigningEnabled
+}
+
+// GetPreferredTransferClient get preferred_transfer_client
+func (s S3Settings) GetPreferredTransferClient() string {
+	return s.PreferredTransferClient
+}
@@ -0,0 +1,164 @@
+package awsprofile_test
+
+import (
+	"fmt"
+	"io/ioutil"
+	"log"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/youyo/awsprofile"
+)
+
+func ExampleConfigs_GetS3() {
+	configs := awsprofile.NewConfigs()
+	if err := configs.Parse("./tests/nested/.aws/config"); err != nil {
+		log.Fatal(err)
+	}
+
+	s3, err := configs.GetS3("transfer")
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	fmt.Println(s3.GetMaxConcurrentRequests(), s3.GetMultipartThreshold(), s3.GetAddressingStyle())
+	// Output: 20 64MB path
+}
+
+func TestConfigs_Parse_Nested(t *testing.T) {
+	configs := awsprofile.NewConfigs()
+	if err := configs.Parse("./tests/nested/.aws/config"); err != nil {
+		t.Fatal(err)
+	}
+
+	config := findConfig(configs, "transfer")
+
+	expect := awsprofile.S3Settings{
+		MaxConcurrentRequests: 20,
+		MaxQueueSize:          10000,
+		MultipartThreshold:    "64MB",
+		AddressingStyle:       "path",
+		UseAccelerateEndpoint: true,
+	}
+	if config.GetS3() != expect {
+		t.Error("Unmatched S3", config.GetS3())
+	}
+
+	if config.GetS3API().GetAddressingStyle() != "virtual" {
+		t.Error("Unmatched S3API", config.GetS3API())
+	}
+
+	if config.GetNested("s3")["x_custom"] != "keep" {
+		t.Error("Unknown nested key is lost", config.Nested)
+	}
+
+	if value, err := configs.GetNested("transfer", "dynamodb"); err != nil {
+		t.Error(err)
+	} else if value["endpoint_url"] != "http://localhost:8000" {
+		t.Error("Unmatched Nested", value)
+	}
+
+	if config.GetOutput() != "json" || config.GetRegion() != "us-east-1" {
+		t.Error("keys after nested keys are lost", config.GetOutput(), config.GetRegion())
+	}
+}
+
+func TestConfigs_Save_Nested(t *testing.T) {
+	dir := tempDir(t)
+	defer os.RemoveAll(dir)
+
+	file := filepath.Join(dir, "config")
+	copyFile(t, "./tests/nested/.aws/config", file)
+
+	configs := awsprofile.NewConfigs()
+	if err := configs.Parse(file); err != nil {
+		t.Fatal(err)
+	}
+
+	config := findConfig(configs, "transfer")
+	config.S3.MaxConcurrentRequests = 50
+	config.Unset("s3.max_queue_size")
+	config.Unset("s3api")
+	config.Nested["dynamodb"]["endpoint_url"] = "http://localhost:8001"
+	config.Nested["sqs"] = map[string]string{"endpoint_url": "http://localhost:9324"}
+	configs.Set(config)
+	configs.Set(awsprofile.Config{ProfileName: "new", S3: awsprofile.S3Settings{AddressingStyle: "path"}})
+
+	if err := configs.Save(file); err != nil {
+		t.Fatal(err)
+	}
+
+	assertFile(t, file, `[profile transfer]
+region = us-east-1
+s3 =
+  max_concurrent_requests = 50
+  multipart_threshold = 64MB
+  addressing_style = path
+  use_accelerate_endpoint = true
+  x_custom = keep
+dynamodb =
+  endpoint_url = http://localhost:8001
+output = json
+sqs =
+  endpoint_url = http://localhost:9324
+
+[profile new]
+s3 =
+  addressing_style = path
+`)
+
+	saved := awsprofile.NewConfigs()
+	if err := saved.Parse(file); err != nil {
+		t.Fatal(err)
+	}
+
+	if value, _ := saved.GetS3("transfer"); value.MaxConcurrentRequests != 50 {
+		t.Fatal("Unmatched S3", value)
+	}
+}
+
+func TestConfigs_Save_NestedZero(t *testing.T) {
+	dir := tempDir(t)
+	defer os.RemoveAll(dir)
+
+	data := `[profile zero]
+s3 =
+  max_queue_size = 0
+  use_accelerate_endpoint = false
+  payload_signing_enabled = false
+s3api =
+  use_dualstack_endpoint = false
+`
+
+	file := filepath.Join(dir, "config")
+	if err := ioutil.WriteFile(file, []byte(data), awsprofile.FileMode); err != nil {
+		t.Fatal(err)
+	}
+
+	configs := awsprofile.NewConfigs()
+	if err := configs.Parse(file); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := configs.Save(file); err != nil {
+		t.Fatal(err)
+	}
+
+	assertFile(t, file, data)
+
+	config := findConfig(configs, "zero")
+	config.Unset("s3.use_accelerate_endpoint")
+	config.Unset("s3api.use_dualstack_endpoint")
+	configs.Set(config)
+
+	if err := configs.Save(file); err != nil {
+		t.Fatal(err)
+	}
+
+	assertFile(t, file, `[profile zero]
+s3 =
+  max_queue_size = 0
+  payload_signing_enabled = false
+`)
+}
@@ -0,0 +1,304 @@
+package awsprofile
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"sort"
+	"strings"
+)
+
+// constant
+const (
+	SERVICES_SECTION string = "services"
+)
+
+// error messages
+var (
+	ErrorNotFoundServicesSection error = errors.New(SERVICES_SECTION + " section" + ErrorNotFound)
+)
+
+// Service provide a [services name] section of config file.
+// Settings has nested keys of each service identifier such as s3 and dynamodb,
+// and Extra has keys which have no nested keys.
+type Service struct {
+	Name     string
+	Settings map[string]map[string]string
+	Extra    map[string]string
+}
+
+// Services has many Service
+type Services []Service
+
+// NewServices create a new Services instance
+func NewServices() *Services {
+	return new(Services)
+}
+
+// Parse services sections of config file, and replace services with them
+func (s *Services) Parse(configFile string) error {
+	return parseFile(configFile, false, s.parse)
+}
+
+// ParseBytes parse data like Parse.
+// source is used as the path of *ParseError, e.g. the name of a secret, and can be empty.
+func (s *Services) ParseBytes(data []byte, source string) error {
+	return s.parse(data, source, false)
+}
+
+// ParseReader read r to the end and parse it like ParseBytes
+func (s *Services) ParseReader(r io.Reader, source string) error {
+	return parseReader(r, source, false, s.parse)
+}
+
+// parse skips sections which have invalid lines when lenient.
+// Errors of lines are not returned then, because Configs reports them.
+func (s *Services) parse(data []byte, source string, lenient bool) error {
+	d, _, err := parseSource(data, source, lenient)
+	if err != nil {
+		return err
+	}
+
+	parsed := Services{}
+
+	for _, section := range d.sections {
+		if section.invalid() {
+			continue
+		}
+
+		name, ok := prefixedSectionName(section, SERVICES_SECTION)
+		if !ok {
+			continue
+		}
+
+		service := Service{}
+
+		service.Name = name
+		service.Settings = make(map[string]map[string]string)
+
+		for _, kv := range section.keyValues() {
+			if kv.nested == nil {
+				setExtra(&service.Extra, kv.key, kv.value)
+				continue
+			}
+
+			settings := make(map[string]string)
+			for _, nested := range kv.nested {
+				settings[nested.key] = nested.value
+			}
+
+			service.Settings[kv.key] = settings
+		}
+
+		parsed.Set(service)
+	}
+
+	*s = parsed
+
+	return nil
+}
+
+// Set add a services section, or replace the services section of the same name
+func (s *Services) Set(service Service) {
+	for i := range *s {
+		if (*s)[i].Name == service.Name {
+			(*s)[i] = service
+			return
+		}
+	}
+
+	*s = append(*s, service)
+}
+
+// Delete remove a services section
+func (s *Services) Delete(name string) bool {
+	for i := range *s {
+		if (*s)[i].Name == name {
+			*s = append((*s)[:i], (*s)[i+1:]...)
+			return true
+		}
+	}
+
+	return false
+}
+
+// WriteTo write services sections in config file format
+func (s *Services) WriteTo(w io.Writer) (int64, error) {
+	d := newDocument()
+	if err := s.patch(d); err != nil {
+		return 0, err
+	}
+
+	return bytes.NewBuffer(d.Bytes()).WriteTo(w)
+}
+
+// Save write services sections of config file atomically.
+// Profiles and other sections of configFile are kept as they are.
+func (s *Services) Save(configFile string) error {
+	d, err := loadDocument(configFile, false)
+	if err != nil {
+		return err
+	}
+
+	if err := s.patch(d); err != nil {
+		return err
+	}
+
+	return writeFileAtomic(configFile, d.Bytes())
+}
+
+// patch make services sections of d the same as s
+func (s *Services) patch(d *document) error {
+	sections := make(map[string]*section)
+
+	for _, section := range append([]*section(nil), d.sections...) {
+		name, ok := prefixedSectionName(section, SERVICES_SECTION)
+		if !ok {
+			continue
+		}
+
+		if _, ok := s.get(name); !ok {
+			d.removeSection(section)
+			continue
+		}
+
+		sections[name] = section
+	}
+
+	for _, service := range *s {
+		kvs := service.keyValues()
+		if err := checkSection(service.Name, kvs); err != nil {
+			return err
+		}
+
+		section, ok := sections[service.Name]
+		if !ok {
+			section = d.addSection(SERVICES_SECTION + " " + service.Name)
+		}
+
+		// service identifiers are all known keys of the section
+		var known []string
+		for _, kv := range section.keyValues() {
+			known = append(known, kv.key)
+		}
+
+		section.apply(kvs, known)
+	}
+
+	return nil
+}
+
+// Names get name of services sections
+func (s *Services) Names() ([]string, error) {
+	var names []string
+
+	for _, service := range *s {
+		names = append(names, service.Name)
+	}
+
+	return names, nil
+}
+
+// GetEndpointURL get endpoint_url of a service in a services section
+func (s *Services) GetEndpointURL(name, service string) (string, error) {
+	if section, ok := s.get(name); ok {
+		return section.GetEndpointURL(service), nil
+	}
+
+	return EmptyString, s.notFound(name, EmptyString)
+}
+
+// notFound returns NotFoundError of a services section which is not in s. profile refers it, or is empty.
+func (s *Services) notFound(name, profile string) error {
+	names, _ := s.Names()
+	return sectionNotFound(SERVICES_SECTION, name, profile, names, ErrorNotFoundServicesSection)
+}
+
+func (s *Services) get(name string) (*Service, bool) {
+	for i := range *s {
+		if (*s)[i].Name == name {
+			return &(*s)[i], true
+		}
+	}
+
+	return nil, false
+}
+
+// keyValues returns service identifiers in sorted order, followed by keys of Extra
+func (s *Service) keyValues() []keyValue {
+	var ids []string
+	for id := range s.Settings {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	var kvs []keyValue
+
+	for _, id := range ids {
+		kv := keyValue{key: id}
+		kv.nested = sortedKeyValues(s.Settings[id])
+
+		if kv.nested != nil {
+			kvs = append(kvs, kv)
+		}
+	}
+
+	kvs = appendExtra(kvs, s.Extra)
+
+	return kvs
+}
+
+// GetEndpointURL get endpoint_url of a service
+func (s *Service) GetEndpointURL(service string) string {
+	return s.Settings[ServiceIdentifier(service)][ENDPOINT_URL]
+}
+
+// ServiceIdentifier returns the key of a service in a services section,
+// which is lower case with spaces and hyphens replaced by underscores. e.g. "Elastic Beanstalk" is elastic_beanstalk.
+func ServiceIdentifier(service string) string {
+	return strings.NewReplacer(" ", "_", "-", "_").Replace(strings.ToLower(service))
+}
+
+// GetEndpointURL get the endpoint URL which the AWS CLI uses for a service with a profile.
+// endpoint_url of the services section of the profile takes precedence over endpoint_url of the profile,
+// and nothing is used when ignore_configured_endpoint_urls is true.
+func (a *AwsProfile) GetEndpointURL(profile, service string) (string, error) {
+	snapshot := a.Snapshot()
+
+	resolved, err := snapshot.Resolve(profile)
+	if err != nil {
+		return EmptyString, err
+	}
+
+	if resolved.IgnoreConfiguredEndpointURLs {
+		return EmptyString, nil
+	}
+
+	if resolved.Services != EmptyString {
+		section, ok := snapshot.Services.get(resolved.Services)
+		if !ok {
+			return EmptyString, snapshot.Services.notFound(resolved.Services, profile)
+		}
+
+		if endpointURL := section.GetEndpointURL(service); endpointURL != EmptyString {
+			return endpointURL, nil
+		}
+	}
+
+	return resolved.EndpointURL, nil
+}
+
+func sortedKeyValues(m map[string]string) []keyValue {
+	var keys []string
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var kvs []keyValue
+	for _, key := range keys {
+		kvs = append(kvs, keyValue{key: key, value: m[key]})
+	}
+
+	return kvs
+}
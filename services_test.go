@@ -0,0 +1,204 @@
+package awsprofile_test
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/youyo/awsprofile"
+)
+
+func ExampleAwsProfile_GetEndpointURL() {
+	// if you use non-default configuration file path
+	if err := os.Setenv("AWS_SHARED_CREDENTIALS_FILE", "./tests/services/.aws/credentials"); err != nil {
+		log.Fatal(err)
+	}
+	if err := os.Setenv("AWS_CONFIG_FILE", "./tests/services/.aws/config"); err != nil {
+		log.Fatal(err)
+	}
+
+	awsProfile := awsprofile.New()
+	if err := awsProfile.Parse(); err != nil {
+		log.Fatal(err)
+	}
+
+	// endpoint_url of [services local-services]
+	s3, err := awsProfile.GetEndpointURL("local", "s3")
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	// endpoint_url of [profile local]
+	sqs, err := awsProfile.GetEndpointURL("local", "sqs")
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	fmt.Println(s3, sqs)
+	// Output: http://localhost:4567 http://localhost:4566
+}
+
+func TestServices_Parse(t *testing.T) {
+	awsProfile := newProfile(t, "./tests/services")
+
+	profiles, _ := awsProfile.GetConfigs().ProfileNames()
+	if fmt.Sprint(profiles) != "[local ignore global dangling]" {
+		t.Fatal("Unexpected profiles", profiles)
+	}
+
+	names, _ := awsProfile.GetServices().Names()
+	if fmt.Sprint(names) != "[local-services]" {
+		t.Fatal("Unexpected services", names)
+	}
+
+	if value, err := awsProfile.GetServices().GetEndpointURL("local-services", "dynamodb"); err != nil {
+		t.Fatal(err)
+	} else if value != "http://localhost:8000" {
+		t.Fatal("Unmatched EndpointURL", value)
+	}
+
+	_, err := awsProfile.GetServices().GetEndpointURL("local-service", "s3")
+	if !errors.Is(err, awsprofile.ErrorNotFoundServicesSection) {
+		t.Fatal("Unexpected error", err)
+	}
+
+	if err.Error() != `services section "local-service" is not found, did you mean "local-services"?` {
+		t.Error("Unmatched message", err)
+	}
+}
+
+func TestAwsProfile_GetEndpointURL(t *testing.T) {
+	awsProfile := newProfile(t, "./tests/services")
+
+	tests := []struct {
+		profile string
+		service string
+		expect  string
+	}{
+		{"local", "s3", "http://localhost:4567"},
+		{"local", "S3", "http://localhost:4567"},
+		{"local", "Elastic Beanstalk", "http://localhost:9000"},
+		{"local", "sqs", "http://localhost:4566"},
+		{"ignore", "s3", ""},
+		{"global", "s3", "https://global.example.com"},
+	}
+
+	for _, tt := range tests {
+		value, err := awsProfile.GetEndpointURL(tt.profile, tt.service)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if value != tt.expect {
+			t.Error(tt.profile, tt.service, value, "expect", tt.expect)
+		}
+	}
+
+	_, err := awsProfile.GetEndpointURL("dangling", "s3")
+	if !errors.Is(err, awsprofile.ErrorNotFoundServicesSection) || errors.Is(err, awsprofile.ErrorNotFoundProfile) {
+		t.Error("Unexpected error", err)
+	}
+
+	var notFound *awsprofile.NotFoundError
+	if !errors.As(err, &notFound) || notFound.Profile != "dangling" || notFound.Section != "services" {
+		t.Error("Unexpected NotFoundError", err)
+	}
+}
+
+func TestConfigs_GetIgnoreConfiguredEndpointURLs(t *testing.T) {
+	awsProfile := newProfile(t, "./tests/services")
+
+	if value, err := awsProfile.GetConfigs().GetIgnoreConfiguredEndpointURLs("ignore"); err != nil {
+		t.Fatal(err)
+	} else if !value {
+		t.Fatal("Unmatched IgnoreConfiguredEndpointURLs")
+	}
+}
+
+func TestServices_Save(t *testing.T) {
+	dir := tempDir(t)
+	defer os.RemoveAll(dir)
+
+	file := filepath.Join(dir, "config")
+	copyFile(t, "./tests/services/.aws/config", file)
+
+	services := awsprofile.NewServices()
+	if err := services.Parse(file); err != nil {
+		t.Fatal(err)
+	}
+
+	service := awsprofile.Service{
+		Name: "local-services",
+		Settings: map[string]map[string]string{
+			"s3":       {"endpoint_url": "http://localhost:4567"},
+			"dynamodb": {"endpoint_url": "http://localhost:8001"},
+			"sqs":      {"endpoint_url": "http://localhost:9324"},
+		},
+	}
+	services.Set(service)
+
+	if err := services.Save(file); err != nil {
+		t.Fatal(err)
+	}
+
+	assertFile(t, file, `[profile local]
+services = local-services
+endpoint_url = http://localhost:4566
+region = us-east-1
+
+[profile ignore]
+services = local-services
+endpoint_url = http://localhost:4566
+ignore_configured_endpoint_urls = true
+
+[profile global]
+endpoint_url = https://global.example.com
+
+[profile dangling]
+services = nothing
+
+# LocalStack
+[services local-services]
+s3 =
+    endpoint_url = http://localhost:4567
+dynamodb =
+    endpoint_url = http://localhost:8001
+sqs =
+    endpoint_url = http://localhost:9324
+`)
+}
+
+func TestServices_Save_Extra(t *testing.T) {
+	dir := tempDir(t)
+	defer os.RemoveAll(dir)
+
+	data := `[services x]
+foo = bar
+s3 =
+  endpoint_url = http://localhost:4567
+`
+
+	file := filepath.Join(dir, "config")
+	if err := ioutil.WriteFile(file, []byte(data), awsprofile.FileMode); err != nil {
+		t.Fatal(err)
+	}
+
+	services := awsprofile.NewServices()
+	if err := services.Parse(file); err != nil {
+		t.Fatal(err)
+	}
+
+	if service := (*services)[0]; service.Extra["foo"] != "bar" {
+		t.Fatal("Unmatched Extra", service.Extra)
+	}
+
+	if err := services.Save(file); err != nil {
+		t.Fatal(err)
+	}
+
+	assertFile(t, file, data)
+}
@@ -0,0 +1,292 @@
+package awsprofile
+
+import (
+	"bytes"
+	"errors"
+	"io"
+)
+
+// constant
+const (
+	SSO_SESSION_SECTION     string = "sso-session"
+	SSO_REGISTRATION_SCOPES string = "sso_registration_scopes"
+)
+
+// error messages
+var (
+	ErrorNotFoundSSOSessionSection     error = errors.New(SSO_SESSION_SECTION + " section" + ErrorNotFound)
+	ErrorNotFoundSSORegistrationScopes error = errors.New(SSO_REGISTRATION_SCOPES + ErrorNotFound)
+)
+
+// SSOSession provide a [sso-session name] section of config file
+type SSOSession struct {
+	Name                  string
+	SSOStartURL           string
+	SSORegion             string
+	SSORegistrationScopes string
+	origins               origins
+}
+
+// SSOSessions has many SSOSession
+type SSOSessions []SSOSession
+
+// NewSSOSessions create a new SSOSessions instance
+func NewSSOSessions() *SSOSessions {
+	return new(SSOSessions)
+}
+
+// Parse sso-session sections of config file, and replace sso-sessions with them
+func (s *SSOSessions) Parse(configFile string) error {
+	return parseFile(configFile, false, s.parse)
+}
+
+// ParseBytes parse data like Parse.
+// source is used as the path of *ParseError, e.g. the name of a secret, and can be empty.
+func (s *SSOSessions) ParseBytes(data []byte, source string) error {
+	return s.parse(data, source, false)
+}
+
+// ParseReader read r to the end and parse it like ParseBytes
+func (s *SSOSessions) ParseReader(r io.Reader, source string) error {
+	return parseReader(r, source, false, s.parse)
+}
+
+// parse skips sections which have invalid lines when lenient.
+// Errors of lines are not returned then, because Configs reports them.
+func (s *SSOSessions) parse(data []byte, source string, lenient bool) error {
+	d, _, err := parseSource(data, source, lenient)
+	if err != nil {
+		return err
+	}
+
+	parsed := SSOSessions{}
+
+	for _, section := range d.sections {
+		if section.invalid() {
+			continue
+		}
+
+		name, ok := prefixedSectionName(section, SSO_SESSION_SECTION)
+		if !ok {
+			continue
+		}
+
+		session := SSOSession{}
+
+		session.Name = name
+
+		for _, kv := range section.keyValues() {
+			session.setValue(kv.key, kv.value)
+			session.origins.set(kv.key, Origin{Path: source, Line: kv.number, Section: section.name})
+		}
+
+		parsed.Set(session)
+	}
+
+	*s = parsed
+
+	return nil
+}
+
+// Set add a sso-session, or replace the sso-session of the same name
+func (s *SSOSessions) Set(session SSOSession) {
+	for i := range *s {
+		if (*s)[i].Name == session.Name {
+			(*s)[i] = session
+			return
+		}
+	}
+
+	*s = append(*s, session)
+}
+
+// Delete remove a sso-session
+func (s *SSOSessions) Delete(name string) bool {
+	for i := range *s {
+		if (*s)[i].Name == name {
+			*s = append((*s)[:i], (*s)[i+1:]...)
+			return true
+		}
+	}
+
+	return false
+}
+
+// WriteTo write sso-sessions in config file format
+func (s *SSOSessions) WriteTo(w io.Writer) (int64, error) {
+	d := newDocument()
+	if err := s.patch(d); err != nil {
+		return 0, err
+	}
+
+	return bytes.NewBuffer(d.Bytes()).WriteTo(w)
+}
+
+// Save write sso-session sections of config file atomically.
+// Profiles and other sections of configFile are kept as they are.
+func (s *SSOSessions) Save(configFile string) error {
+	d, err := loadDocument(configFile, false)
+	if err != nil {
+		return err
+	}
+
+	if err := s.patch(d); err != nil {
+		return err
+	}
+
+	return writeFileAtomic(configFile, d.Bytes())
+}
+
+// patch make sso-session sections of d the same as s
+func (s *SSOSessions) patch(d *document) error {
+	sections := make(map[string]*section)
+
+	for _, section := range append([]*section(nil), d.sections...) {
+		name, ok := prefixedSectionName(section, SSO_SESSION_SECTION)
+		if !ok {
+			continue
+		}
+
+		if _, ok := s.get(name); !ok {
+			d.removeSection(section)
+			continue
+		}
+
+		sections[name] = section
+	}
+
+	for _, session := range *s {
+		kvs := session.keyValues()
+		if err := checkSection(session.Name, kvs); err != nil {
+			return err
+		}
+
+		section, ok := sections[session.Name]
+		if !ok {
+			section = d.addSection(SSO_SESSION_SECTION + " " + session.Name)
+		}
+
+		section.apply(kvs, ssoSessionKeys)
+	}
+
+	return nil
+}
+
+// Names get name of sso-sessions
+func (s *SSOSessions) Names() ([]string, error) {
+	var names []string
+
+	for _, session := range *s {
+		names = append(names, session.Name)
+	}
+
+	return names, nil
+}
+
+// GetSSOStartURL get sso_start_url
+func (s *SSOSessions) GetSSOStartURL(name string) (string, error) {
+	if session, ok := s.get(name); ok {
+		return session.SSOStartURL, nil
+	}
+
+	return EmptyString, s.notFound(name, EmptyString, ErrorNotFoundSSOStartURL)
+}
+
+// GetSSORegion get sso_region
+func (s *SSOSessions) GetSSORegion(name string) (string, error) {
+	if session, ok := s.get(name); ok {
+		return session.SSORegion, nil
+	}
+
+	return EmptyString, s.notFound(name, EmptyString, ErrorNotFoundSSORegion)
+}
+
+// GetSSORegistrationScopes get sso_registration_scopes
+func (s *SSOSessions) GetSSORegistrationScopes(name string) (string, error) {
+	if session, ok := s.get(name); ok {
+		return session.SSORegistrationScopes, nil
+	}
+
+	return EmptyString, s.notFound(name, EmptyString, ErrorNotFoundSSORegistrationScopes)
+}
+
+// notFound returns NotFoundError of a sso-session which is not in s. profile refers it, or is empty.
+func (s *SSOSessions) notFound(name, profile string, err error) error {
+	names, _ := s.Names()
+	return sectionNotFound(SSO_SESSION_SECTION, name, profile, names, err)
+}
+
+func (s *SSOSessions) get(name string) (*SSOSession, bool) {
+	for i := range *s {
+		if (*s)[i].Name == name {
+			return &(*s)[i], true
+		}
+	}
+
+	return nil, false
+}
+
+// ssoSessionKeys are keys of SSOSession in the order of writing
+var ssoSessionKeys = []string{
+	SSO_START_URL,
+	SSO_REGION,
+	SSO_REGISTRATION_SCOPES,
+}
+
+// setValue set a value of sso-session section. Unknown keys are ignored.
+func (s *SSOSession) setValue(key, value string) {
+	switch key {
+	case SSO_START_URL:
+		s.SSOStartURL = value
+	case SSO_REGION:
+		s.SSORegion = value
+	case SSO_REGISTRATION_SCOPES:
+		s.SSORegistrationScopes = value
+	}
+}
+
+func (s *SSOSession) keyValues() []keyValue {
+	var kvs []keyValue
+
+	kvs = appendKeyValue(kvs, SSO_START_URL, s.SSOStartURL)
+	kvs = appendKeyValue(kvs, SSO_REGION, s.SSORegion)
+	kvs = appendKeyValue(kvs, SSO_REGISTRATION_SCOPES, s.SSORegistrationScopes)
+
+	return kvs
+}
+
+// GetSSOStartURL get sso_start_url
+func (s *SSOSession) GetSSOStartURL() string {
+	return s.SSOStartURL
+}
+
+// GetSSORegion get sso_region
+func (s *SSOSession) GetSSORegion() string {
+	return s.SSORegion
+}
+
+// GetSSORegistrationScopes get sso_registration_scopes
+func (s *SSOSession) GetSSORegistrationScopes() string {
+	return s.SSORegistrationScopes
+}
+
+// GetSSOSession get the sso-session which a profile refers by sso_session
+func (a *AwsProfile) GetSSOSession(profile string) (*SSOSession, error) {
+	snapshot := a.Snapshot()
+
+	ok, config := snapshot.IsConfig(profile)
+	if !ok {
+		return nil, snapshot.notFound(profile)
+	}
+
+	if config.SSOSession == EmptyString {
+		return nil, keyNotFound(profile, SSO_SESSION, ErrorNotFoundSSOSession)
+	}
+
+	session, ok := snapshot.SSOSessions.get(config.SSOSession)
+	if !ok {
+		return nil, snapshot.SSOSessions.notFound(config.SSOSession, profile, ErrorNotFoundSSOSessionSection)
+	}
+
+	return session, nil
+}
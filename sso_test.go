@@ -0,0 +1,195 @@
+package awsprofile_test
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/youyo/awsprofile"
+)
+
+func ExampleAwsProfile_GetSSOSession() {
+	// if you use non-default configuration file path
+	if err := os.Setenv("AWS_SHARED_CREDENTIALS_FILE", "./tests/sso/.aws/credentials"); err != nil {
+		log.Fatal(err)
+	}
+	if err := os.Setenv("AWS_CONFIG_FILE", "./tests/sso/.aws/config"); err != nil {
+		log.Fatal(err)
+	}
+
+	awsProfile := awsprofile.New()
+	if err := awsProfile.Parse(); err != nil {
+		log.Fatal(err)
+	}
+
+	// Get the sso-session of a profile
+	session, err := awsProfile.GetSSOSession("sso-dev")
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	fmt.Println(session.Name, session.GetSSOStartURL())
+	// Output: my-sso https://my-sso.awsapps.com/start
+}
+
+func TestSSOSessions_Parse(t *testing.T) {
+	awsProfile := newProfile(t, "./tests/sso")
+
+	profiles, _ := awsProfile.GetConfigs().ProfileNames()
+	if fmt.Sprint(profiles) != "[sso-legacy sso-dev sso-admin sso-dangling]" {
+		t.Fatal("Unexpected profiles", profiles)
+	}
+
+	names, _ := awsProfile.GetSSOSessions().Names()
+	if fmt.Sprint(names) != "[my-sso]" {
+		t.Fatal("Unexpected sso-sessions", names)
+	}
+
+	if value, err := awsProfile.GetSSOSessions().GetSSORegistrationScopes("my-sso"); err != nil {
+		t.Fatal(err)
+	} else if value != "sso:account:access" {
+		t.Fatal("Unmatched SSORegistrationScopes", value)
+	}
+}
+
+func TestConfigs_GetSSOAccountID(t *testing.T) {
+	awsProfile := newProfile(t, "./tests/sso")
+
+	if value, err := awsProfile.GetConfigs().GetSSOAccountID("sso-legacy"); err != nil {
+		t.Fatal(err)
+	} else if value != "111111111111" {
+		t.Fatal("Unmatched SSOAccountID", value)
+	}
+
+	if value, err := awsProfile.GetConfigs().GetSSORoleName("sso-dev"); err != nil {
+		t.Fatal(err)
+	} else if value != "Developer" {
+		t.Fatal("Unmatched SSORoleName", value)
+	}
+}
+
+func TestAwsProfile_GetSSOSession(t *testing.T) {
+	awsProfile := newProfile(t, "./tests/sso")
+
+	if _, err := awsProfile.GetSSOSession("sso-legacy"); !errors.Is(err, awsprofile.ErrorNotFoundSSOSession) {
+		t.Error("Unexpected error", err)
+	}
+
+	_, err := awsProfile.GetSSOSession("sso-dangling")
+	if !errors.Is(err, awsprofile.ErrorNotFoundSSOSessionSection) {
+		t.Error("Unexpected error", err)
+	}
+
+	if err.Error() != `sso-session section "nothing" is not found for profile "sso-dangling"` {
+		t.Error("Unmatched message", err)
+	}
+
+	_, err = awsProfile.GetSSOSessions().GetSSOStartURL("my-ss")
+	if !errors.Is(err, awsprofile.ErrorNotFoundSSOStartURL) || !errors.Is(err, awsprofile.ErrorNotFoundSSOSessionSection) {
+		t.Error("Unexpected error", err)
+	}
+
+	if err.Error() != `sso-session section "my-ss" is not found, did you mean "my-sso"?` {
+		t.Error("Unmatched message", err)
+	}
+
+	if _, err := awsProfile.GetSSOSession("fooooo"); !errors.Is(err, awsprofile.ErrorNotFoundProfile) {
+		t.Error("Unexpected error", err)
+	}
+}
+
+func TestAwsProfile_Resolve_SSOSession(t *testing.T) {
+	awsProfile := newProfile(t, "./tests/sso")
+
+	profile, err := awsProfile.Resolve("sso-dev")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if profile.Session == nil || profile.Session.Name != "my-sso" {
+		t.Fatal("Unmatched Session", profile.Session)
+	}
+
+	if profile.GetSSOStartURL() != "https://my-sso.awsapps.com/start" || profile.GetSSORegion() != "ap-northeast-1" {
+		t.Fatal("sso-session is not merged", profile.GetSSOStartURL(), profile.GetSSORegion())
+	}
+
+	profile, err = awsProfile.Resolve("sso-dangling")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if profile.Session != nil || profile.GetSSOAccountID() != "333333333333" {
+		t.Fatal("Unmatched profile of dangling sso_session", profile.Session, profile.GetSSOAccountID())
+	}
+
+	if _, err := awsProfile.Effective(map[string]string{"AWS_PROFILE": "sso-dangling"}); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestAwsProfile_Chain_SSO(t *testing.T) {
+	awsProfile := newProfile(t, "./tests/sso")
+
+	chain, err := awsProfile.Chain("sso-admin")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(chain) != 2 || chain[1].ProfileName != "sso-dev" || chain[1].Kind != awsprofile.ChainSSO {
+		t.Fatal("Unexpected chain", chain)
+	}
+
+	chain, err = awsProfile.Chain("sso-legacy")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if chain[0].Kind != awsprofile.ChainSSO {
+		t.Fatal("Unexpected kind", chain[0].Kind)
+	}
+}
+
+func TestSSOSessions_Save(t *testing.T) {
+	dir := tempDir(t)
+	defer os.RemoveAll(dir)
+
+	file := filepath.Join(dir, "config")
+	copyFile(t, "./tests/sso/.aws/config", file)
+
+	sessions := awsprofile.NewSSOSessions()
+	if err := sessions.Parse(file); err != nil {
+		t.Fatal(err)
+	}
+
+	sessions.Set(awsprofile.SSOSession{Name: "my-sso", SSOStartURL: "https://new.awsapps.com/start", SSORegion: "us-east-1"})
+
+	if err := sessions.Save(file); err != nil {
+		t.Fatal(err)
+	}
+
+	configs := awsprofile.NewConfigs()
+	if err := configs.Parse(file); err != nil {
+		t.Fatal(err)
+	}
+
+	if profiles, _ := configs.ProfileNames(); len(profiles) != 4 {
+		t.Fatal("profiles are changed", profiles)
+	}
+
+	saved := awsprofile.NewSSOSessions()
+	if err := saved.Parse(file); err != nil {
+		t.Fatal(err)
+	}
+
+	if value, _ := saved.GetSSOStartURL("my-sso"); value != "https://new.awsapps.com/start" {
+		t.Fatal("Unmatched SSOStartURL", value)
+	}
+
+	if value, _ := saved.GetSSORegistrationScopes("my-sso"); value != "" {
+		t.Fatal("Unmatched SSORegistrationScopes", value)
+	}
+}
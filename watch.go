@@ -0,0 +1,132 @@
+package awsprofile
+
+import (
+	"context"
+	"reflect"
+	"time"
+)
+
+// DefaultWatchInterval is the interval of Watch checking credential file and config file without WithWatchInterval
+const DefaultWatchInterval = time.Second
+
+// Diff is the profiles changed by a reload.
+// Err is the error of the reload, e.g. *ParseError while a file is being edited,
+// and then the profiles parsed before are kept.
+type Diff struct {
+	Added    []string
+	Removed  []string
+	Modified []string
+	Err      error
+}
+
+// Empty reports whether no profiles are changed and no error occurred
+func (d Diff) Empty() bool {
+	return len(d.Added) == 0 && len(d.Removed) == 0 && len(d.Modified) == 0 && d.Err == nil
+}
+
+// Watch reload credential file and config file every DefaultWatchInterval or WithWatchInterval until ctx is done,
+// and calls fn when profiles are changed.
+// Files are polled by path, modification time and size, so a file which an editor saves by renaming a new file to it is also reloaded.
+// Changes are reported against the profiles which fn saw last, so Parse and Reload called by other goroutines are reported too.
+// An error of a reload is passed to fn once until the error changes. Watch returns ctx.Err().
+func (a *AwsProfile) Watch(ctx context.Context, fn func(Diff)) error {
+	reported := a.Snapshot()
+
+	ticker := time.NewTicker(reported.options.watchInterval())
+	defer ticker.Stop()
+
+	var last error
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+
+		_, err := a.Reload()
+		if err != nil && (last == nil || err.Error() != last.Error()) {
+			fn(Diff{Err: err})
+		}
+		last = err
+
+		current := a.Snapshot()
+		if current.sameStores(reported) {
+			continue
+		}
+
+		if diff := current.diff(reported); !diff.Empty() {
+			fn(diff)
+		}
+		reported = current
+	}
+}
+
+// sameStores reports whether a and other have the same stores, which Parse and Reload swap for new ones
+func (a *AwsProfile) sameStores(other *AwsProfile) bool {
+	return a.Credentials == other.Credentials && a.Configs == other.Configs &&
+		a.SSOSessions == other.SSOSessions && a.Services == other.Services
+}
+
+// diff returns the profiles changed from before.
+// A profile is modified when its credential, config, or the sso-session or services section it refers is changed.
+func (a *AwsProfile) diff(before *AwsProfile) Diff {
+	var diff Diff
+
+	names, _ := a.ProfileNames()
+	beforeNames, _ := before.ProfileNames()
+
+	exists := make(map[string]bool)
+	for _, name := range beforeNames {
+		exists[name] = true
+	}
+
+	for _, name := range names {
+		if !exists[name] {
+			diff.Added = append(diff.Added, name)
+			continue
+		}
+
+		if !reflect.DeepEqual(a.profileKeyValues(name), before.profileKeyValues(name)) {
+			diff.Modified = append(diff.Modified, name)
+		}
+
+		delete(exists, name)
+	}
+
+	for _, name := range beforeNames {
+		if exists[name] {
+			diff.Removed = append(diff.Removed, name)
+		}
+	}
+
+	return diff
+}
+
+// profileKeyValues returns every key of a profile to compare
+func (a *AwsProfile) profileKeyValues(profile string) [][]keyValue {
+	var kvs [][]keyValue
+
+	if ok, credential := a.IsCredential(profile); ok {
+		kvs = append(kvs, credential.keyValues())
+	} else {
+		kvs = append(kvs, nil)
+	}
+
+	ok, config := a.IsConfig(profile)
+	if !ok {
+		return append(kvs, nil)
+	}
+
+	kvs = append(kvs, config.keyValues())
+
+	if session, ok := a.SSOSessions.get(config.SSOSession); ok {
+		kvs = append(kvs, session.keyValues())
+	}
+
+	if service, ok := a.Services.get(config.Services); ok {
+		kvs = append(kvs, service.keyValues())
+	}
+
+	return kvs
+}
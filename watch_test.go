@@ -0,0 +1,107 @@
+package awsprofile_test
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/youyo/awsprofile"
+)
+
+func waitDiff(t *testing.T, diffs chan awsprofile.Diff) awsprofile.Diff {
+	select {
+	case diff := <-diffs:
+		return diff
+	case <-time.After(5 * time.Second):
+		t.Fatal("no change is reported")
+	}
+
+	return awsprofile.Diff{}
+}
+
+func TestAwsProfile_Watch(t *testing.T) {
+	dir := tempDir(t)
+	defer os.RemoveAll(dir)
+
+	credentialsFile := filepath.Join(dir, "credentials")
+	configFile := filepath.Join(dir, "config")
+	copyFile(t, "./tests/.aws/credentials", credentialsFile)
+
+	write := func(file, data string) {
+		if err := ioutil.WriteFile(file, []byte(data), awsprofile.FileMode); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	write(configFile, "[profile a]\nregion = us-east-1\n\n[profile b]\nregion = us-east-1\n")
+
+	os.Setenv("AWS_SHARED_CREDENTIALS_FILE", credentialsFile)
+	os.Setenv("AWS_CONFIG_FILE", configFile)
+
+	awsProfile := awsprofile.New(awsprofile.WithWatchInterval(10 * time.Millisecond))
+	if err := awsProfile.Parse(); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	diffs := make(chan awsprofile.Diff, 10)
+	watched := make(chan error)
+
+	go func() {
+		watched <- awsProfile.Watch(ctx, func(diff awsprofile.Diff) {
+			diffs <- diff
+		})
+	}()
+
+	write(configFile, "[profile a]\nregion = ap-northeast-1\n\n[profile c]\nregion = us-east-1\n")
+
+	diff := waitDiff(t, diffs)
+	if fmt.Sprint(diff.Added, diff.Removed, diff.Modified) != "[c] [b] [a]" || diff.Err != nil {
+		t.Fatal("Unexpected diff", diff)
+	}
+
+	// an editor saves a file by renaming a new file to it
+	tmp := filepath.Join(dir, "config.swp")
+	write(tmp, "[profile a]\nregion = ap-northeast-1\n\n[profile c]\nregion = ap-southeast-2\n")
+	if err := os.Rename(tmp, configFile); err != nil {
+		t.Fatal(err)
+	}
+
+	diff = waitDiff(t, diffs)
+	if fmt.Sprint(diff.Added, diff.Removed, diff.Modified) != "[] [] [c]" {
+		t.Fatal("Unexpected diff", diff)
+	}
+
+	// a reload by another goroutine is reported too
+	write(configFile, "[profile a]\nregion = ap-northeast-1\n\n[profile c]\nregion = ap-southeast-2\n\n[profile d]\n")
+	if _, err := awsProfile.Reload(); err != nil {
+		t.Fatal(err)
+	}
+
+	diff = waitDiff(t, diffs)
+	if fmt.Sprint(diff.Added, diff.Removed, diff.Modified) != "[d] [] []" {
+		t.Fatal("Unexpected diff", diff)
+	}
+
+	// profiles are kept while a file is invalid
+	write(configFile, "[profile a\n")
+
+	diff = waitDiff(t, diffs)
+	if diff.Err == nil {
+		t.Fatal("Unexpected diff", diff)
+	}
+
+	if value, _ := awsProfile.GetConfigs().GetRegion("c"); value != "ap-southeast-2" {
+		t.Fatal("profiles are changed", value)
+	}
+
+	cancel()
+
+	if err := <-watched; err != context.Canceled {
+		t.Fatal("Unexpected error", err)
+	}
+}
@@ -0,0 +1,153 @@
+package awsprofile
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// FileMode is permission of files written by Save
+const FileMode os.FileMode = 0600
+
+// errors of names, keys and values which cannot be written to a file and parsed back as they are
+var (
+	ErrorInvalidName  = errors.New("invalid section name")
+	ErrorInvalidKey   = errors.New("invalid key")
+	ErrorInvalidValue = errors.New("invalid value")
+)
+
+// keyValue is a key of a section. nested holds nested keys of a key with empty value.
+// number is the line number of the key in the file it is parsed from.
+type keyValue struct {
+	key    string
+	value  string
+	number int
+	nested []keyValue
+}
+
+func appendKeyValue(kvs []keyValue, key, value string) []keyValue {
+	if value == EmptyString {
+		return kvs
+	}
+
+	return append(kvs, keyValue{key: key, value: value})
+}
+
+// checkSection returns an error when a section of name and kvs cannot be written to a file and parsed back as they are
+func checkSection(name string, kvs []keyValue) error {
+	if name == EmptyString || name != strings.TrimSpace(name) || strings.ContainsAny(name, "]\r\n") {
+		return fmt.Errorf("%w: %q", ErrorInvalidName, name)
+	}
+
+	for _, kv := range kvs {
+		if err := checkKeyValue(kv, false); err != nil {
+			return fmt.Errorf("%s: %w", name, err)
+		}
+	}
+
+	return nil
+}
+
+// checkKeyValue returns an error when kv cannot be written as a line.
+// A value of a key which is not nested may have more lines, which are written as continuation lines.
+func checkKeyValue(kv keyValue, nested bool) error {
+	if kv.key == EmptyString || kv.key != strings.TrimSpace(kv.key) || strings.ContainsAny(kv.key, "=:\r\n") || strings.ContainsAny(kv.key[:1], "[#;") {
+		return fmt.Errorf("%w: %q", ErrorInvalidKey, kv.key)
+	}
+
+	if strings.Contains(kv.value, "\r") || (nested && strings.Contains(kv.value, "\n")) {
+		return fmt.Errorf("%s: %w: %q", kv.key, ErrorInvalidValue, kv.value)
+	}
+
+	lines := strings.Split(kv.value, "\n")
+	for i, l := range lines[1:] {
+		trimmed := strings.TrimSpace(l)
+
+		// a blank line or a comment ends the value, and a key follows an empty first line as a nested key
+		if trimmed == EmptyString || trimmed[0] == '#' || trimmed[0] == ';' || (i == 0 && lines[0] == EmptyString && strings.ContainsAny(trimmed, "=:")) {
+			return fmt.Errorf("%s: %w: %q", kv.key, ErrorInvalidValue, kv.value)
+		}
+	}
+
+	for _, child := range kv.nested {
+		if err := checkKeyValue(child, true); err != nil {
+			return fmt.Errorf("%s.%w", kv.key, err)
+		}
+	}
+
+	return nil
+}
+
+// loadDocument read filename to edit. A file which does not exist is an empty document.
+// Lines which cannot be parsed are kept as they are when lenient is true.
+func loadDocument(filename string, lenient bool) (*document, error) {
+	data, err := ioutil.ReadFile(filename)
+	if os.IsNotExist(err) {
+		return newDocument(), nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	d, errs := parseDocument(data)
+	if errs != nil && !lenient {
+		errs.setPath(filename)
+		return nil, errs[0]
+	}
+
+	return d, nil
+}
+
+// writeFileAtomic write data to a temporary file and rename it to filename,
+// so filename is never left half-written. A symlink is kept, and the file it links to is written.
+func writeFileAtomic(filename string, data []byte) error {
+	filename, err := resolveSymlink(filename)
+	if err != nil {
+		return err
+	}
+
+	dir := filepath.Dir(filename)
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return err
+	}
+
+	tmp, err := ioutil.TempFile(dir, "."+filepath.Base(filename)+".tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if err = tmp.Chmod(FileMode); err != nil {
+		tmp.Close()
+		return err
+	}
+
+	if _, err = tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+
+	if err = tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+
+	if err = tmp.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), filename)
+}
+
+// resolveSymlink returns the file which filename links to, or filename itself when it does not exist
+func resolveSymlink(filename string) (string, error) {
+	resolved, err := filepath.EvalSymlinks(filename)
+	if os.IsNotExist(err) {
+		return filename, nil
+	}
+
+	return resolved, err
+}
@@ -0,0 +1,301 @@
+package awsprofile_test
+
+import (
+	"bytes"
+	"errors"
+	"io/ioutil"
+	"log"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/youyo/awsprofile"
+)
+
+func ExampleConfigs_WriteTo() {
+	configs := awsprofile.NewConfigs()
+	configs.Set(awsprofile.Config{
+		ProfileName: "default",
+		Region:      "ap-northeast-1",
+	})
+	configs.Set(awsprofile.Config{
+		ProfileName:   "bar",
+		RoleArn:       "arn:aws:iam::xxxxxxxxxxxx:role/bar",
+		SourceProfile: "default",
+	})
+
+	if _, err := configs.WriteTo(os.Stdout); err != nil {
+		log.Fatal(err)
+	}
+	// Output:
+	// [default]
+	// region = ap-northeast-1
+	//
+	// [profile bar]
+	// role_arn = arn:aws:iam::xxxxxxxxxxxx:role/bar
+	// source_profile = default
+}
+
+func ExampleCredentials_WriteTo() {
+	creds := awsprofile.NewCredentials()
+	creds.Set(awsprofile.Credential{
+		ProfileName:        "foo",
+		AwsAccessKeyID:     "ACCESS-2-XXXXXXXXXXXXX",
+		AwsSecretAccessKey: "SECRET-2-XXXXXXXXXXXXX",
+	})
+
+	if _, err := creds.WriteTo(os.Stdout); err != nil {
+		log.Fatal(err)
+	}
+	// Output:
+	// [foo]
+	// aws_access_key_id = ACCESS-2-XXXXXXXXXXXXX
+	// aws_secret_access_key = SECRET-2-XXXXXXXXXXXXX
+}
+
+func tempDir(t *testing.T) string {
+	dir, err := ioutil.TempDir("", "awsprofile")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return dir
+}
+
+func assertFileMode(t *testing.T, file string) {
+	info, err := os.Stat(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if info.Mode().Perm() != awsprofile.FileMode {
+		t.Fatal("Unexpected file mode", info.Mode().Perm())
+	}
+}
+
+func TestConfigs_Save(t *testing.T) {
+	dir := tempDir(t)
+	defer os.RemoveAll(dir)
+
+	configs := awsprofile.NewConfigs()
+	if err := configs.Parse("./tests/.aws/config"); err != nil {
+		t.Fatal(err)
+	}
+
+	configs.Set(awsprofile.Config{ProfileName: "new", Region: "us-west-2", DurationSeconds: 3600})
+	if !configs.Delete("barbar") {
+		t.Fatal("barbar is not deleted")
+	}
+	if configs.Delete("fooooo") {
+		t.Fatal("Unexpected delete")
+	}
+
+	file := filepath.Join(dir, "config")
+	if err := configs.Save(file); err != nil {
+		t.Fatal(err)
+	}
+
+	assertFileMode(t, file)
+
+	saved := awsprofile.NewConfigs()
+	if err := saved.Parse(file); err != nil {
+		t.Fatal(err)
+	}
+
+	assertExported(t, saved.List(), configs.List())
+}
+
+func TestConfigs_Save_Written(t *testing.T) {
+	dir := tempDir(t)
+	defer os.RemoveAll(dir)
+
+	data := `[profile written]
+duration_seconds = +900
+max_attempts = 03
+use_fips_endpoint = TRUE
+s3 =
+  max_concurrent_requests = 010
+  use_accelerate_endpoint = False
+`
+
+	file := filepath.Join(dir, "config")
+	if err := ioutil.WriteFile(file, []byte(data), awsprofile.FileMode); err != nil {
+		t.Fatal(err)
+	}
+
+	configs := awsprofile.NewConfigs()
+	if err := configs.Parse(file); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := configs.Save(file); err != nil {
+		t.Fatal(err)
+	}
+
+	assertFile(t, file, data)
+
+	config := findConfig(configs, "written")
+	config.MaxAttempts = 5
+	config.S3.UseAccelerateEndpoint = true
+	configs.Set(config)
+
+	if err := configs.Save(file); err != nil {
+		t.Fatal(err)
+	}
+
+	assertFile(t, file, `[profile written]
+duration_seconds = +900
+max_attempts = 5
+use_fips_endpoint = TRUE
+s3 =
+  max_concurrent_requests = 010
+  use_accelerate_endpoint = true
+`)
+}
+
+func TestCredentials_Save(t *testing.T) {
+	dir := tempDir(t)
+	defer os.RemoveAll(dir)
+
+	creds := awsprofile.NewCredentials()
+	if err := creds.Parse("./tests/.aws/credentials"); err != nil {
+		t.Fatal(err)
+	}
+
+	creds.Set(awsprofile.Credential{ProfileName: "foo", AwsAccessKeyID: "ACCESS-NEW", AwsSecretAccessKey: "SECRET-NEW"})
+	creds.Delete("foobar")
+
+	file := filepath.Join(dir, "credentials")
+	if err := creds.Save(file); err != nil {
+		t.Fatal(err)
+	}
+
+	assertFileMode(t, file)
+
+	saved := awsprofile.NewCredentials()
+	if err := saved.Parse(file); err != nil {
+		t.Fatal(err)
+	}
+
+	assertExported(t, saved.List(), creds.List())
+
+	if value, _ := saved.GetAwsAccessKeyID("foo"); value != "ACCESS-NEW" {
+		t.Fatal("Unmatched AwsAccessKeyID", value)
+	}
+}
+
+func TestConfigs_Save_Symlink(t *testing.T) {
+	dir := tempDir(t)
+	defer os.RemoveAll(dir)
+
+	target := filepath.Join(dir, "dotfiles", "config")
+	if err := os.MkdirAll(filepath.Dir(target), 0700); err != nil {
+		t.Fatal(err)
+	}
+	copyFile(t, "./tests/.aws/config", target)
+
+	file := filepath.Join(dir, "config")
+	if err := os.Symlink(target, file); err != nil {
+		t.Fatal(err)
+	}
+
+	configs := awsprofile.NewConfigs()
+	if err := configs.Parse(file); err != nil {
+		t.Fatal(err)
+	}
+
+	configs.Set(awsprofile.Config{ProfileName: "new", Region: "us-west-2"})
+
+	if err := configs.Save(file); err != nil {
+		t.Fatal(err)
+	}
+
+	info, err := os.Lstat(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if info.Mode()&os.ModeSymlink == 0 {
+		t.Fatal("symlink is replaced", info.Mode())
+	}
+
+	saved := awsprofile.NewConfigs()
+	if err := saved.Parse(target); err != nil {
+		t.Fatal(err)
+	}
+
+	if value, _ := saved.GetRegion("new"); value != "us-west-2" {
+		t.Fatal("the file which the symlink links to is not written", value)
+	}
+}
+
+func TestConfigs_Save_MultiLine(t *testing.T) {
+	dir := tempDir(t)
+	defer os.RemoveAll(dir)
+
+	file := filepath.Join(dir, "config")
+	if err := ioutil.WriteFile(file, []byte("[profile a]\ncredential_process = foo\n  --bar\n"), awsprofile.FileMode); err != nil {
+		t.Fatal(err)
+	}
+
+	configs := awsprofile.NewConfigs()
+	if err := configs.Parse(file); err != nil {
+		t.Fatal(err)
+	}
+
+	a := findConfig(configs, "a")
+	configs.Set(awsprofile.Config{ProfileName: "b", CredentialProcess: a.CredentialProcess})
+
+	if err := configs.Save(file); err != nil {
+		t.Fatal(err)
+	}
+
+	assertFile(t, file, "[profile a]\ncredential_process = foo\n  --bar\n\n[profile b]\ncredential_process = foo\n  --bar\n")
+
+	saved := awsprofile.NewConfigs()
+	if err := saved.Parse(file); err != nil {
+		t.Fatal(err)
+	}
+
+	assertExported(t, saved.List(), configs.List())
+}
+
+func TestSave_Invalid(t *testing.T) {
+	dir := tempDir(t)
+	defer os.RemoveAll(dir)
+
+	file := filepath.Join(dir, "credentials")
+	copyFile(t, "./tests/.aws/credentials", file)
+	original, _ := ioutil.ReadFile(file)
+
+	credentials := awsprofile.NewCredentials()
+	if err := credentials.Parse(file); err != nil {
+		t.Fatal(err)
+	}
+
+	credentials.Set(awsprofile.Credential{ProfileName: "x]\n[evil", AwsAccessKeyID: "ACCESS", AwsSecretAccessKey: "SECRET"})
+
+	if err := credentials.Save(file); !errors.Is(err, awsprofile.ErrorInvalidName) {
+		t.Fatal("Unexpected error", err)
+	}
+
+	assertFile(t, file, string(original))
+
+	for _, test := range []struct {
+		config awsprofile.Config
+		err    error
+	}{
+		{awsprofile.Config{ProfileName: "a", Extra: map[string]string{"x=y": "z"}}, awsprofile.ErrorInvalidKey},
+		{awsprofile.Config{ProfileName: "a", Region: "us-east-1\n# evil"}, awsprofile.ErrorInvalidValue},
+		{awsprofile.Config{ProfileName: "a", CredentialProcess: "foo\n\n--bar"}, awsprofile.ErrorInvalidValue},
+		{awsprofile.Config{ProfileName: "a", S3: awsprofile.S3Settings{AddressingStyle: "path\nevil = true"}}, awsprofile.ErrorInvalidValue},
+	} {
+		configs := awsprofile.NewConfigs()
+		configs.Set(test.config)
+
+		var buf bytes.Buffer
+		if _, err := configs.WriteTo(&buf); !errors.Is(err, test.err) {
+			t.Error("Unexpected error", test.config, err)
+		}
+	}
+}